@@ -0,0 +1,238 @@
+/*
+Package a2s implements the Source server query protocol over UDP: A2S_INFO, A2S_PLAYER and A2S_RULES.
+
+Unlike the Steam Web API used by the serverlist package, the queries go directly to game servers
+and return live data: current players with scores and play time, server rules and extended info.
+Challenge numbers, multi-packet split responses, bzip2 compressed responses and the GoldSrc
+response formats are handled by the Client.
+
+Reference: [Server queries]
+
+# Example usage:
+
+	package main
+
+	import (
+		"fmt"
+		"log"
+
+		"github.com/woozymasta/steam/a2s"
+	)
+
+	func main() {
+		client := a2s.New()
+
+		info, err := client.Info("127.0.0.1:27016")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s on %s [%d/%d]\n", info.Name, info.Map, info.Players, info.MaxPlayers)
+
+		players, err := client.Players("127.0.0.1:27016")
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, p := range players {
+			fmt.Printf("%s %d %s\n", p.Name, p.Score, p.Duration)
+		}
+	}
+
+[Server queries]: https://developer.valvesoftware.com/wiki/Server_queries
+*/
+package a2s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// DefaultTimeout defines the default timeout of a single query including all packets of the response.
+	DefaultTimeout = 3 * time.Second
+
+	// DefaultConcurrency defines the default number of servers queried simultaneously by Batch.
+	DefaultConcurrency = 64
+
+	// maxChallenges is the number of challenge responses accepted before giving up
+	maxChallenges = 3
+
+	// maxDatagram is the size of the UDP read buffer
+	maxDatagram = 65535
+
+	// maxDecompressed is the limit of the decompressed size of a compressed response
+	maxDecompressed = 1 << 20
+)
+
+// Request and response headers
+const (
+	headerInfo          = 'T'
+	headerPlayer        = 'U'
+	headerRules         = 'V'
+	headerChallenge     = 'A'
+	headerInfoSource    = 'I'
+	headerInfoGoldSrc   = 'm'
+	headerPlayerReply   = 'D'
+	headerRulesReply    = 'E'
+	packetSimple        = -1
+	packetSplit         = -2
+	compressedSplitFlag = 0x80000000
+)
+
+var (
+	// ErrMalformed is returned when the response can not be decoded.
+	ErrMalformed = errors.New("malformed response")
+
+	// ErrChallenge is returned when the server keeps responding with new challenge numbers.
+	ErrChallenge = errors.New("too many challenge responses")
+)
+
+// Client queries game servers.
+type Client struct {
+	timeout     time.Duration
+	concurrency int
+	goldSrc     bool
+}
+
+// New creates a new Client with the default timeout and concurrency.
+func New() *Client {
+	return &Client{
+		timeout:     DefaultTimeout,
+		concurrency: DefaultConcurrency,
+	}
+}
+
+// SetTimeout sets the timeout of a single query to a server, including challenge and all packets.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// SetConcurrency sets the number of servers queried simultaneously by Batch.
+func (c *Client) SetConcurrency(concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	c.concurrency = concurrency
+}
+
+// SetGoldSrc enables the GoldSrc format of split packets used by Half-Life 1 engine servers.
+// Source and GoldSrc split packets can not be reliably distinguished, so it must be set explicitly.
+func (c *Client) SetGoldSrc(goldSrc bool) {
+	c.goldSrc = goldSrc
+}
+
+// Info queries A2S_INFO of the server at the "IP:Port" query address.
+func (c *Client) Info(addr string) (*Info, error) {
+	return c.InfoContext(context.Background(), addr)
+}
+
+// InfoContext is like Info but with the context to cancel the query.
+func (c *Client) InfoContext(ctx context.Context, addr string) (*Info, error) {
+	base := append([]byte{0xFF, 0xFF, 0xFF, 0xFF, headerInfo}, "Source Engine Query\x00"...)
+
+	data, err := c.query(ctx, addr, func(challenge []byte) []byte {
+		return append(append([]byte(nil), base...), challenge...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseInfo(data)
+}
+
+// Players queries A2S_PLAYER of the server at the "IP:Port" query address.
+func (c *Client) Players(addr string) ([]Player, error) {
+	return c.PlayersContext(context.Background(), addr)
+}
+
+// PlayersContext is like Players but with the context to cancel the query.
+func (c *Client) PlayersContext(ctx context.Context, addr string) ([]Player, error) {
+	data, err := c.query(ctx, addr, challengeRequest(headerPlayer))
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePlayers(data)
+}
+
+// Rules queries A2S_RULES of the server at the "IP:Port" query address.
+func (c *Client) Rules(addr string) (map[string]string, error) {
+	return c.RulesContext(context.Background(), addr)
+}
+
+// RulesContext is like Rules but with the context to cancel the query.
+func (c *Client) RulesContext(ctx context.Context, addr string) (map[string]string, error) {
+	data, err := c.query(ctx, addr, challengeRequest(headerRules))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRules(data)
+}
+
+// challengeRequest returns the request builder for queries with challenge number, -1 requests a challenge
+func challengeRequest(header byte) func(challenge []byte) []byte {
+	return func(challenge []byte) []byte {
+		if challenge == nil {
+			challenge = []byte{0xFF, 0xFF, 0xFF, 0xFF}
+		}
+		return append([]byte{0xFF, 0xFF, 0xFF, 0xFF, header}, challenge...)
+	}
+}
+
+// query sends the request and returns the response payload after the packet header,
+// the request is repeated with the challenge number while the server responds with a challenge
+func (c *Client) query(ctx context.Context, addr string, request func(challenge []byte) []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect %s: %w", addr, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var deadline time.Time
+	if c.timeout > 0 {
+		deadline = time.Now().Add(c.timeout)
+	}
+	ctxDeadline, ok := ctx.Deadline()
+	if ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	if !deadline.IsZero() {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	var challenge []byte
+	for i := 0; i < maxChallenges; i++ {
+		if _, err := conn.Write(request(challenge)); err != nil {
+			return nil, fmt.Errorf("failed to send request to %s: %w", addr, err)
+		}
+
+		data, err := c.receive(conn)
+		if err != nil {
+			// The socket deadline can pass before the context is done
+			if ok && ctx.Err() == nil && !time.Now().Before(ctxDeadline) {
+				<-ctx.Done()
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to read response from %s: %w", addr, err)
+		}
+
+		if len(data) >= 5 && data[0] == headerChallenge {
+			challenge = append([]byte(nil), data[1:5]...)
+			continue
+		}
+
+		return data, nil
+	}
+
+	return nil, ErrChallenge
+}
@@ -0,0 +1,296 @@
+package a2s_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/woozymasta/steam/a2s"
+	"github.com/woozymasta/steam/serverlist"
+)
+
+// rulesBzip2 is bzip2 compressed A2S_RULES response with rules mod=dayz and time=12:00
+const (
+	rulesBzip2 = "425a6839314159265359db61264100000dcd80d00070100200262284300000a000229883350da42869a60022862b4103c05795925990d1bb7c5dc914e142436d849904"
+	rulesSize  = 27
+	rulesCRC   = 0xe61aba1f
+)
+
+var challenge = []byte{0x11, 0x22, 0x33, 0x44}
+
+// packet builds test packets
+type packet struct {
+	bytes.Buffer
+}
+
+func newPacket() *packet { return &packet{} }
+
+func (p *packet) simple() *packet        { return p.raw(0xFF, 0xFF, 0xFF, 0xFF) }
+func (p *packet) split() *packet         { return p.raw(0xFE, 0xFF, 0xFF, 0xFF) }
+func (p *packet) raw(b ...byte) *packet  { p.Write(b); return p }
+func (p *packet) join(b []byte) *packet  { p.Write(b); return p }
+func (p *packet) chars(s string) *packet { p.WriteString(s); return p }
+func (p *packet) str(s string) *packet   { p.WriteString(s); p.WriteByte(0); return p }
+func (p *packet) u8(v byte) *packet      { p.WriteByte(v); return p }
+func (p *packet) u16(v uint16) *packet   { return p.le(v) }
+func (p *packet) u32(v uint32) *packet   { return p.le(v) }
+func (p *packet) u64(v uint64) *packet   { return p.le(v) }
+func (p *packet) i32(v int32) *packet    { return p.le(v) }
+func (p *packet) f32(v float32) *packet  { return p.le(math.Float32bits(v)) }
+func (p *packet) le(v any) *packet       { _ = binary.Write(p, binary.LittleEndian, v); return p }
+func (p *packet) data() []byte           { return append([]byte(nil), p.Bytes()...) }
+
+func (p *packet) bool(v bool) *packet {
+	if v {
+		return p.u8(1)
+	}
+	return p.u8(0)
+}
+
+// standIn is a local UDP game server answering A2S queries with prepared packets
+type standIn struct {
+	conn    *net.UDPConn
+	respond func(request []byte) [][]byte
+}
+
+func newStandIn(t *testing.T, respond func(request []byte) [][]byte) string {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	s := &standIn{conn: conn, respond: respond}
+	go s.serve()
+
+	return conn.LocalAddr().String()
+}
+
+func (s *standIn) serve() {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		for _, p := range s.respond(append([]byte(nil), buf[:n]...)) {
+			_, _ = s.conn.WriteToUDP(p, addr)
+		}
+	}
+}
+
+// withChallenge responds with the challenge until the request ends with it
+func withChallenge(request []byte, reply func() [][]byte) [][]byte {
+	if !bytes.HasSuffix(request, challenge) {
+		return [][]byte{newPacket().simple().u8('A').raw(challenge...).data()}
+	}
+
+	return reply()
+}
+
+func sourceInfo() []byte {
+	return newPacket().simple().u8('I').u8(17).
+		str("DayZ Test").str("chernarusplus").str("dayz").str("DayZ").u16(0).
+		u8(5).u8(60).u8(0).chars("d").chars("l").bool(false).bool(true).str("1.26.159040").
+		u8(0x80 | 0x10 | 0x20 | 0x01).u16(2302).u64(90200000000000001).str("battleye,lqs0").u64(221100).
+		data()
+}
+
+func sourcePlayers() []byte {
+	p := newPacket().simple().u8('D').u8(2)
+	p.u8(0).str("Survivor").i32(10).f32(90.5)
+	p.u8(1).str("Bandit").i32(-1).f32(3)
+	return p.data()
+}
+
+func TestInfo(t *testing.T) {
+	addr := newStandIn(t, func(request []byte) [][]byte {
+		if request[4] != 'T' || !bytes.Contains(request, []byte("Source Engine Query\x00")) {
+			return nil
+		}
+		return withChallenge(request, func() [][]byte { return [][]byte{sourceInfo()} })
+	})
+
+	info, err := a2s.New().Info(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Name != "DayZ Test" || info.Map != "chernarusplus" || info.Players != 5 || info.MaxPlayers != 60 ||
+		info.Version != "1.26.159040" || info.Port != 2302 || info.SteamID != 90200000000000001 ||
+		info.Keywords != "battleye,lqs0" || info.AppID() != 221100 || !info.VAC || info.Password ||
+		info.ServerType != a2s.ServerDedicated || info.Environment != serverlist.OSLinux {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
+
+func TestPlayersSplit(t *testing.T) {
+	payload := sourcePlayers()
+	half := len(payload) / 2
+
+	addr := newStandIn(t, func(request []byte) [][]byte {
+		if request[4] != 'U' {
+			return nil
+		}
+		return withChallenge(request, func() [][]byte {
+			// parts are sent in reverse order
+			return [][]byte{
+				newPacket().split().u32(7).u8(2).u8(1).u16(1248).join(payload[half:]).data(),
+				newPacket().split().u32(7).u8(2).u8(0).u16(1248).join(payload[:half]).data(),
+			}
+		})
+	})
+
+	players, err := a2s.New().Players(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(players) != 2 || players[0].Name != "Survivor" || players[0].Score != 10 ||
+		players[0].Duration != 90500*time.Millisecond || players[1].Name != "Bandit" || players[1].Score != -1 {
+		t.Errorf("unexpected players %+v", players)
+	}
+}
+
+func TestRulesCompressed(t *testing.T) {
+	compressed, _ := hex.DecodeString(rulesBzip2)
+	half := len(compressed) / 2
+
+	standIn := func(size uint32) string {
+		return newStandIn(t, func(request []byte) [][]byte {
+			if request[4] != 'V' {
+				return nil
+			}
+			return withChallenge(request, func() [][]byte {
+				return [][]byte{
+					newPacket().split().u32(0x80000009).u8(2).u8(0).u16(1248).u32(size).u32(rulesCRC).
+						join(compressed[:half]).data(),
+					newPacket().split().u32(0x80000009).u8(2).u8(1).u16(1248).join(compressed[half:]).data(),
+				}
+			})
+		})
+	}
+
+	rules, err := a2s.New().Rules(standIn(rulesSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules["mod"] != "dayz" || rules["time"] != "12:00" {
+		t.Errorf("unexpected rules %v", rules)
+	}
+
+	if _, err := a2s.New().Rules(standIn(64 << 20)); !errors.Is(err, a2s.ErrMalformed) {
+		t.Errorf("Rules() with 64 MiB decompressed size = %v, expected ErrMalformed", err)
+	}
+}
+
+func TestGoldSrc(t *testing.T) {
+	info := newPacket().simple().u8('m').str("127.0.0.1:27015").str("HLDS").str("crossfire").str("valve").
+		str("Half-Life").u8(3).u8(16).u8(47).chars("D").chars("W").bool(true).
+		u8(1).str("http://mod").str("http://mod/dl").u8(0).u32(2).u32(1024).bool(true).bool(false).
+		bool(true).u8(1).data()
+
+	addr := newStandIn(t, func(request []byte) [][]byte {
+		return [][]byte{
+			newPacket().split().u32(5).u8(0x12).join(info[10:]).data(),
+			newPacket().split().u32(5).u8(0x02).join(info[:10]).data(),
+		}
+	})
+
+	client := a2s.New()
+	client.SetGoldSrc(true)
+
+	got, err := client.Info(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.GoldSrc || got.Name != "HLDS" || got.Address != "127.0.0.1:27015" || got.Bots != 1 || !got.Password ||
+		got.ServerType != a2s.ServerDedicated || got.Environment != serverlist.OSWindows ||
+		got.Mod == nil || got.Mod.Size != 1024 || !got.Mod.MultiPlayer || !got.VAC {
+		t.Errorf("unexpected info %+v, mod %+v", got, got.Mod)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	silent := newStandIn(t, func([]byte) [][]byte { return nil })
+	garbage := newStandIn(t, func([]byte) [][]byte { return [][]byte{{0xFF, 0xFF, 0xFF, 0xFF, 'X', 1}} })
+	challenges := newStandIn(t, func([]byte) [][]byte {
+		return [][]byte{newPacket().simple().u8('A').raw(challenge...).data()}
+	})
+
+	client := a2s.New()
+	client.SetTimeout(100 * time.Millisecond)
+
+	var netErr net.Error
+	if _, err := client.Info(silent); !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("Info() of silent server = %v, expected timeout", err)
+	}
+	if _, err := client.Players(garbage); !errors.Is(err, a2s.ErrMalformed) {
+		t.Errorf("Players() of garbage = %v, expected ErrMalformed", err)
+	}
+	if _, err := client.Rules(challenges); !errors.Is(err, a2s.ErrChallenge) {
+		t.Errorf("Rules() = %v, expected ErrChallenge", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	client.SetTimeout(time.Minute)
+	if _, err := client.InfoContext(ctx, silent); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("InfoContext() = %v, expected context deadline", err)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	addr := newStandIn(t, func(request []byte) [][]byte {
+		return withChallenge(request, func() [][]byte {
+			switch request[4] {
+			case 'T':
+				return [][]byte{sourceInfo()}
+			case 'U':
+				return [][]byte{sourcePlayers()}
+			}
+			return [][]byte{newPacket().simple().u8('E').u16(1).str("a").str("b").data()}
+		})
+	})
+	silent := newStandIn(t, func([]byte) [][]byte { return nil })
+
+	client := a2s.New()
+	client.SetTimeout(100 * time.Millisecond)
+	client.SetConcurrency(2)
+
+	servers := serverlist.Servers{{Addr: addr}, {Addr: silent}, {Addr: addr}}
+	results := client.Batch(context.Background(), servers, a2s.QueryAll)
+	if len(results) != 3 {
+		t.Fatalf("Batch() returned %d results", len(results))
+	}
+
+	for _, i := range []int{0, 2} {
+		r := results[i]
+		if r.Err != nil || r.Info == nil || len(r.Players) != 2 || r.Rules["a"] != "b" || r.Server.Addr != addr {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+	if results[1].Err == nil || results[1].Info != nil {
+		t.Errorf("result of silent server = %+v, expected error", results[1])
+	}
+
+	results = client.Batch(context.Background(), servers[:1], a2s.QueryInfo)
+	if results[0].Info == nil || results[0].Players != nil || results[0].Rules != nil {
+		t.Errorf("Batch() with QueryInfo = %+v", results[0])
+	}
+
+	// Zero value client has no concurrency set
+	var zero a2s.Client
+	results = zero.Batch(context.Background(), servers[:1], a2s.QueryInfo)
+	if results[0].Err != nil || results[0].Info == nil {
+		t.Errorf("Batch() of zero value client = %+v", results[0])
+	}
+}
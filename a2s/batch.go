@@ -0,0 +1,84 @@
+package a2s
+
+import (
+	"context"
+	"sync"
+
+	"github.com/woozymasta/steam/serverlist"
+)
+
+// Query is a set of queries sent to each server by Batch.
+type Query int
+
+const (
+	QueryInfo    Query = 1 << iota // A2S_INFO
+	QueryPlayers                   // A2S_PLAYER
+	QueryRules                     // A2S_RULES
+
+	// QueryAll sends all queries.
+	QueryAll = QueryInfo | QueryPlayers | QueryRules
+)
+
+// Result is the result of queries to a server by Batch.
+type Result struct {
+	Err     error             // First error of the queries, other queries are still sent
+	Info    *Info             // A2S_INFO response, nil if not queried or failed
+	Rules   map[string]string // A2S_RULES response
+	Players []Player          // A2S_PLAYER response
+	Server  serverlist.Server // Queried server
+}
+
+// Batch sends the queries concurrently to all servers using Server.Addr as the query address.
+// Every server has its own timeout, results are returned in the order of servers.
+func (c *Client) Batch(ctx context.Context, servers serverlist.Servers, query Query) []Result {
+	results := make([]Result, len(servers))
+	sem := make(chan struct{}, max(c.concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i := range servers {
+		results[i].Server = servers[i]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r *Result) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.batchQuery(ctx, r, query)
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results
+}
+
+// batchQuery sends the queries to the server of the result
+func (c *Client) batchQuery(ctx context.Context, r *Result, query Query) {
+	addr := r.Server.Addr
+	keep := func(err error) {
+		if r.Err == nil {
+			r.Err = err
+		}
+	}
+
+	if query&QueryInfo != 0 && ctx.Err() == nil {
+		info, err := c.InfoContext(ctx, addr)
+		keep(err)
+		r.Info = info
+	}
+	if query&QueryPlayers != 0 && ctx.Err() == nil {
+		players, err := c.PlayersContext(ctx, addr)
+		keep(err)
+		r.Players = players
+	}
+	if query&QueryRules != 0 && ctx.Err() == nil {
+		rules, err := c.RulesContext(ctx, addr)
+		keep(err)
+		r.Rules = rules
+	}
+
+	if ctx.Err() != nil {
+		keep(ctx.Err())
+	}
+}
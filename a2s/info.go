@@ -0,0 +1,252 @@
+package a2s
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/woozymasta/steam/serverlist"
+)
+
+// Extra data flags of A2S_INFO
+const (
+	edfPort     = 0x80
+	edfSteamID  = 0x10
+	edfSourceTV = 0x40
+	edfKeywords = 0x20
+	edfGameID   = 0x01
+)
+
+// appIDTheShip is the application ID of The Ship with additional fields in A2S_INFO
+const appIDTheShip = 2400
+
+// ServerType is the type of the server.
+type ServerType byte
+
+const (
+	ServerDedicated ServerType = 'd' // Dedicated server
+	ServerListen    ServerType = 'l' // Non-dedicated server
+	ServerProxy     ServerType = 'p' // SourceTV relay (proxy)
+)
+
+// String returns the server type name.
+func (t ServerType) String() string {
+	switch t {
+	case ServerDedicated:
+		return "dedicated"
+	case ServerListen:
+		return "listen"
+	case ServerProxy:
+		return "proxy"
+	}
+
+	return string(rune(t))
+}
+
+// Info is the A2S_INFO response.
+type Info struct {
+	Mod          *GoldSrcMod   // Mod information of GoldSrc servers, nil if not a mod
+	Name         string        // Server name
+	Map          string        // Current map
+	Folder       string        // Game directory (e.g., "dayz", "cstrike")
+	Game         string        // Game name
+	Version      string        // Game version
+	Keywords     string        // Tags of the server (sv_tags), the same as game type in serverlist
+	SourceTVName string        // Name of the SourceTV spectator server
+	Address      string        // Server address, only in GoldSrc response
+	Environment  serverlist.OS // Operating system of the server
+	SteamID      uint64        // Server SteamID
+	GameID       uint64        // Full game ID, the low 24 bits are the application ID
+	ID           uint16        // Steam application ID, 0 for applications with ID over 65535 (see GameID)
+	Port         uint16        // Game port
+	SourceTVPort uint16        // Port of the SourceTV spectator server
+	Protocol     byte          // Protocol version
+	Players      byte          // Number of players
+	MaxPlayers   byte          // Maximum number of players
+	Bots         byte          // Number of bots
+	ServerType   ServerType    // Type of the server
+	Password     bool          // Server requires a password
+	VAC          bool          // Server uses VAC
+	GoldSrc      bool          // Response is in the obsolete GoldSrc format
+}
+
+// AppID returns the application ID of the server from GameID or ID.
+func (i *Info) AppID() uint64 {
+	if i.GameID != 0 {
+		return i.GameID & 0xFFFFFF
+	}
+
+	return uint64(i.ID)
+}
+
+// GoldSrcMod is the mod information of the GoldSrc A2S_INFO response.
+type GoldSrcMod struct {
+	Link         string // Mod website
+	DownloadLink string // Mod download link
+	Version      uint32 // Mod version
+	Size         uint32 // Mod size in bytes
+	MultiPlayer  bool   // Mod is multiplayer only
+	OwnDLL       bool   // Mod uses its own DLL
+}
+
+// Player is a player of the A2S_PLAYER response.
+type Player struct {
+	Name     string        // Player name
+	Duration time.Duration // Time the player has been connected
+	Score    int32         // Player score
+	Index    byte          // Index of the player chunk
+}
+
+// parseInfo decodes Source or GoldSrc A2S_INFO response
+func parseInfo(data []byte) (*Info, error) {
+	r := &reader{data: data}
+	switch h := r.byte(); h {
+	case headerInfoSource:
+		return parseSourceInfo(r)
+	case headerInfoGoldSrc:
+		return parseGoldSrcInfo(r)
+	default:
+		return nil, fmt.Errorf("%w: unexpected A2S_INFO header 0x%02x", ErrMalformed, h)
+	}
+}
+
+// parseSourceInfo decodes A2S_INFO response after the 'I' header
+func parseSourceInfo(r *reader) (*Info, error) {
+	info := &Info{
+		Protocol: r.byte(),
+		Name:     r.string(),
+		Map:      r.string(),
+		Folder:   r.string(),
+		Game:     r.string(),
+		ID:       r.uint16(),
+	}
+	info.Players = r.byte()
+	info.MaxPlayers = r.byte()
+	info.Bots = r.byte()
+	info.ServerType = ServerType(lower(r.byte()))
+	info.Environment = serverlist.OS([]byte{lower(r.byte())})
+	info.Password = r.byte() == 1
+	info.VAC = r.byte() == 1
+	if info.ID == appIDTheShip {
+		r.next(3) // mode, witnesses, duration
+	}
+	info.Version = r.string()
+
+	if r.more() {
+		edf := r.byte()
+		if edf&edfPort != 0 {
+			info.Port = r.uint16()
+		}
+		if edf&edfSteamID != 0 {
+			info.SteamID = r.uint64()
+		}
+		if edf&edfSourceTV != 0 {
+			info.SourceTVPort = r.uint16()
+			info.SourceTVName = r.string()
+		}
+		if edf&edfKeywords != 0 {
+			info.Keywords = r.string()
+		}
+		if edf&edfGameID != 0 {
+			info.GameID = r.uint64()
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return info, nil
+}
+
+// parseGoldSrcInfo decodes obsolete GoldSrc A2S_INFO response after the 'm' header
+func parseGoldSrcInfo(r *reader) (*Info, error) {
+	info := &Info{
+		GoldSrc: true,
+		Address: r.string(),
+		Name:    r.string(),
+		Map:     r.string(),
+		Folder:  r.string(),
+		Game:    r.string(),
+	}
+	info.Players = r.byte()
+	info.MaxPlayers = r.byte()
+	info.Protocol = r.byte()
+	info.ServerType = ServerType(lower(r.byte()))
+	info.Environment = serverlist.OS([]byte{lower(r.byte())})
+	info.Password = r.byte() == 1
+
+	if r.byte() == 1 {
+		info.Mod = &GoldSrcMod{
+			Link:         r.string(),
+			DownloadLink: r.string(),
+		}
+		r.byte() // null byte
+		info.Mod.Version = r.uint32()
+		info.Mod.Size = r.uint32()
+		info.Mod.MultiPlayer = r.byte() == 1
+		info.Mod.OwnDLL = r.byte() == 1
+	}
+
+	info.VAC = r.byte() == 1
+	info.Bots = r.byte()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return info, nil
+}
+
+// parsePlayers decodes A2S_PLAYER response
+func parsePlayers(data []byte) ([]Player, error) {
+	r := &reader{data: data}
+	if h := r.byte(); h != headerPlayerReply {
+		return nil, fmt.Errorf("%w: unexpected A2S_PLAYER header 0x%02x", ErrMalformed, h)
+	}
+
+	count := int(r.byte())
+	players := make([]Player, 0, count)
+	for i := 0; i < count && r.more(); i++ {
+		p := Player{
+			Index: r.byte(),
+			Name:  r.string(),
+			Score: int32(r.uint32()),
+		}
+		p.Duration = time.Duration(float64(r.float32()) * float64(time.Second))
+		if r.err != nil {
+			return nil, r.err
+		}
+		players = append(players, p)
+	}
+
+	return players, nil
+}
+
+// parseRules decodes A2S_RULES response, values may contain binary data (e.g. DayZ mods)
+func parseRules(data []byte) (map[string]string, error) {
+	r := &reader{data: data}
+	if h := r.byte(); h != headerRulesReply {
+		return nil, fmt.Errorf("%w: unexpected A2S_RULES header 0x%02x", ErrMalformed, h)
+	}
+
+	count := int(r.uint16())
+	rules := make(map[string]string, count)
+	for i := 0; i < count && r.more(); i++ {
+		name, value := r.string(), r.string()
+		if r.err != nil {
+			return nil, r.err
+		}
+		rules[name] = value
+	}
+
+	return rules, nil
+}
+
+// lower returns lowercase ASCII letter, GoldSrc uses uppercase server type and environment
+func lower(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+
+	return b
+}
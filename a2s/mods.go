@@ -0,0 +1,206 @@
+package a2s
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Overflow flags of the mod list
+const (
+	overflowMods       = 0x01
+	overflowSignatures = 0x02
+)
+
+// modDLCFlag marks DLC in the info byte of the mod, the low 4 bits are the length of the workshop ID
+const modDLCFlag = 0x10
+
+// ErrNoMods is returned when the rules have no mod list chunks.
+var ErrNoMods = errors.New("no mod list in rules")
+
+// ModList is the list of mods and DLC decoded from A2S_RULES of DayZ and Arma 3 servers.
+type ModList struct {
+	Mods                []Mod    // Loaded mods and DLC in the order of the server
+	Signatures          []string // Accepted keys of signed addons (Arma 3)
+	DLCHashes           []uint32 // Hashes of DLC enabled in DLC flags
+	DLC                 uint16   // Enabled DLC flags
+	Version             byte     // Version of the protocol
+	Difficulty          byte     // Difficulty flags (Arma 3)
+	ModsTruncated       bool     // Mod list did not fit into the response
+	SignaturesTruncated bool     // Signature list did not fit into the response
+}
+
+// Mod is a mod of ModList.
+type Mod struct {
+	Name       string // Mod name
+	WorkshopID uint64 // Steam Workshop file ID, 0 for mods not from the workshop
+	Hash       uint32 // Hash of the mod
+	DLC        bool   // Mod is a DLC
+}
+
+// WorkshopIDs returns workshop IDs of mods that are not DLC, ready to use with filedetails.New.
+func (l *ModList) WorkshopIDs() []uint64 {
+	ids := make([]uint64, 0, len(l.Mods))
+	for _, m := range l.Mods {
+		if m.WorkshopID != 0 && !m.DLC {
+			ids = append(ids, m.WorkshopID)
+		}
+	}
+
+	return ids
+}
+
+// ParseDayZMods decodes the mod list of the DayZ server from A2S_RULES.
+func ParseDayZMods(rules map[string]string) (*ModList, error) {
+	return parseModList(rules, false)
+}
+
+// ParseArma3Mods decodes the mod list of the Arma 3 server from A2S_RULES,
+// the Arma 3 list also has difficulty and signatures.
+func ParseArma3Mods(rules map[string]string) (*ModList, error) {
+	return parseModList(rules, true)
+}
+
+// parseModList joins the mod list chunks and decodes the data:
+//
+//	version, overflow flags, DLC flags (uint16), difficulty (Arma 3 only),
+//	DLC hashes (uint32 for each DLC flag),
+//	mods count, mods: hash (uint32), info byte, workshop ID (info&0x0F bytes), name,
+//	signatures count, signatures: name
+//
+// strings are prefixed with a length byte
+func parseModList(rules map[string]string, arma3 bool) (*ModList, error) {
+	data, err := modListData(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{data: data}
+	list := &ModList{Version: r.byte()}
+	overflow := r.byte()
+	list.ModsTruncated = overflow&overflowMods != 0
+	list.SignaturesTruncated = overflow&overflowSignatures != 0
+	list.DLC = r.uint16()
+	if arma3 {
+		list.Difficulty = r.byte()
+	}
+
+	for flags := list.DLC; flags != 0; flags &= flags - 1 {
+		list.DLCHashes = append(list.DLCHashes, r.uint32())
+	}
+
+	count := int(r.byte())
+	list.Mods = make([]Mod, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		m := Mod{Hash: r.uint32()}
+		info := r.byte()
+		m.DLC = info&modDLCFlag != 0
+		for j, b := range r.next(int(info & 0x0F)) {
+			m.WorkshopID |= uint64(b) << (8 * j)
+		}
+		m.Name = r.shortString()
+		list.Mods = append(list.Mods, m)
+	}
+
+	if r.more() {
+		count = int(r.byte())
+		for i := 0; i < count && r.err == nil; i++ {
+			list.Signatures = append(list.Signatures, r.shortString())
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return list, nil
+}
+
+// modListData joins the values of rules with 2-byte keys [index, total] in the order of index
+// and unescapes the result. The total is taken from keys with binary bytes, the first chunk
+// always has index 1, so text rule names of two characters are not taken for chunks.
+func modListData(rules map[string]string) ([]byte, error) {
+	type chunk struct {
+		value string
+		index byte
+		total byte
+	}
+
+	var candidates []chunk
+	var total byte
+	for name, value := range rules {
+		key := unescapeRule(name)
+		if len(key) != 2 || key[0] == 0 || key[0] > key[1] {
+			continue
+		}
+		candidates = append(candidates, chunk{index: key[0], total: key[1], value: value})
+
+		if isText(key) {
+			continue
+		}
+		if total != 0 && key[1] != total {
+			return nil, fmt.Errorf("%w: mod list chunks of %d and %d parts", ErrMalformed, total, key[1])
+		}
+		total = key[1]
+	}
+
+	var chunks []chunk
+	for _, c := range candidates {
+		if c.total == total {
+			chunks = append(chunks, c)
+		}
+	}
+
+	if len(chunks) == 0 {
+		return nil, ErrNoMods
+	}
+	if len(chunks) != int(total) {
+		return nil, fmt.Errorf("%w: %d of %d mod list chunks", ErrMalformed, len(chunks), total)
+	}
+
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].index < chunks[j].index })
+
+	var data []byte
+	for _, c := range chunks {
+		data = append(data, c.value...)
+	}
+
+	return unescapeRule(string(data)), nil
+}
+
+// isText reports whether all bytes are printable ASCII
+func isText(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7E {
+			return false
+		}
+	}
+
+	return true
+}
+
+// unescapeRule restores bytes escaped in binary rules: 0x01 0x01 is 0x01, 0x01 0x02 is 0x00 and 0x01 0x03 is 0xFF
+func unescapeRule(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x01 && i+1 < len(s) {
+			switch s[i+1] {
+			case 0x01:
+				b = append(b, 0x01)
+				i++
+				continue
+			case 0x02:
+				b = append(b, 0x00)
+				i++
+				continue
+			case 0x03:
+				b = append(b, 0xFF)
+				i++
+				continue
+			}
+		}
+		b = append(b, s[i])
+	}
+
+	return b
+}
@@ -0,0 +1,95 @@
+package a2s_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/woozymasta/steam/a2s"
+)
+
+// escapeRule escapes 0x01, 0x00 and 0xFF bytes of binary rules
+func escapeRule(b []byte) string {
+	r := strings.NewReplacer("\x01", "\x01\x01", "\x00", "\x01\x02", "\xff", "\x01\x03")
+	return r.Replace(string(b))
+}
+
+// modRules splits the mod list into rules with [index, total] keys
+func modRules(data []byte, size int) map[string]string {
+	escaped := escapeRule(data)
+	total := (len(escaped) + size - 1) / size
+
+	rules := map[string]string{"allowedBuild": "0", "dedicated": "1"}
+	for i := 0; i < total; i++ {
+		end := min(len(escaped), (i+1)*size)
+		rules[escapeRule([]byte{byte(i + 1), byte(total)})] = escaped[i*size : end]
+	}
+
+	return rules
+}
+
+func TestParseDayZMods(t *testing.T) {
+	p := newPacket().u8(3).u8(0).u16(0x0005).u32(0xAABBCCDD).u32(0x01FF0001).u8(3)
+	p.u32(0x11223344).u8(4).u32(1559212036).u8(2).chars("CF")
+	p.u32(0x00FF0100).u8(4).u32(1843990493).u8(16).chars("Community-Online")
+	p.u32(0).u8(0x10).u8(3).chars("DLC")
+
+	rules := modRules(p.data(), 7)
+	rules["ab"] = "text rule"
+	list, err := a2s.ParseDayZMods(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []a2s.Mod{
+		{Name: "CF", WorkshopID: 1559212036, Hash: 0x11223344},
+		{Name: "Community-Online", WorkshopID: 1843990493, Hash: 0x00FF0100},
+		{Name: "DLC", DLC: true},
+	}
+	if !reflect.DeepEqual(list.Mods, expected) {
+		t.Errorf("Mods = %+v\nexpected %+v", list.Mods, expected)
+	}
+	if list.Version != 3 || list.DLC != 5 || !reflect.DeepEqual(list.DLCHashes, []uint32{0xAABBCCDD, 0x01FF0001}) {
+		t.Errorf("unexpected header %+v", list)
+	}
+	if ids := list.WorkshopIDs(); !reflect.DeepEqual(ids, []uint64{1559212036, 1843990493}) {
+		t.Errorf("WorkshopIDs() = %v", ids)
+	}
+}
+
+func TestParseArma3Mods(t *testing.T) {
+	p := newPacket().u8(3).u8(0x01).u16(0).u8(0x42)
+	p.u8(1).u32(0x01010101).u8(4).u32(450814997).u8(4).chars("@CBA")
+	p.u8(2).u8(3).chars("a3\x00").u8(3).chars("cba")
+
+	list, err := a2s.ParseArma3Mods(modRules(p.data(), 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list.Mods) != 1 || list.Mods[0].WorkshopID != 450814997 || list.Mods[0].Name != "@CBA" ||
+		list.Mods[0].Hash != 0x01010101 || list.Difficulty != 0x42 || !list.ModsTruncated || list.SignaturesTruncated {
+		t.Errorf("unexpected mod list %+v", list)
+	}
+	if !reflect.DeepEqual(list.Signatures, []string{"a3\x00", "cba"}) {
+		t.Errorf("Signatures = %q", list.Signatures)
+	}
+}
+
+func TestParseModsErrors(t *testing.T) {
+	if _, err := a2s.ParseDayZMods(map[string]string{"dedicated": "1", "ab": "1"}); !errors.Is(err, a2s.ErrNoMods) {
+		t.Errorf("ParseDayZMods() without mods = %v, expected ErrNoMods", err)
+	}
+
+	rules := modRules(newPacket().u8(3).u8(0).u16(0).u8(1).u32(1).u8(8).data(), 4)
+	if _, err := a2s.ParseDayZMods(rules); !errors.Is(err, a2s.ErrMalformed) {
+		t.Errorf("ParseDayZMods() of truncated list = %v, expected ErrMalformed", err)
+	}
+
+	rules = modRules(newPacket().u8(3).u8(0).u16(0).u8(0).data(), 3)
+	delete(rules, escapeRule([]byte{2, 3}))
+	if _, err := a2s.ParseDayZMods(rules); !errors.Is(err, a2s.ErrMalformed) {
+		t.Errorf("ParseDayZMods() with missing chunk = %v, expected ErrMalformed", err)
+	}
+}
@@ -0,0 +1,245 @@
+package a2s
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"net"
+)
+
+// receive reads a simple or split response and returns the payload after the -1 header
+func (c *Client) receive(conn net.Conn) ([]byte, error) {
+	buf := make([]byte, maxDatagram)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	packet := buf[:n]
+	if len(packet) < 5 {
+		return nil, fmt.Errorf("%w: packet too short", ErrMalformed)
+	}
+
+	switch int32(binary.LittleEndian.Uint32(packet)) {
+	case packetSimple:
+		return packet[4:], nil
+	case packetSplit:
+		return c.receiveSplit(conn, packet)
+	}
+
+	return nil, fmt.Errorf("%w: unknown packet header %x", ErrMalformed, packet[:4])
+}
+
+// splitPacket is a part of the split response
+type splitPacket struct {
+	payload  []byte
+	id       uint32
+	size     uint32 // Decompressed size, only in the first packet of compressed response
+	crc      uint32 // CRC32 of decompressed data, only in the first packet of compressed response
+	total    int
+	number   int
+	compress bool
+}
+
+// receiveSplit reads all parts of the split response started with the first received packet,
+// parts may arrive in any order
+func (c *Client) receiveSplit(conn net.Conn, first []byte) ([]byte, error) {
+	part, err := c.parseSplit(first)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]*splitPacket, part.total)
+	parts[part.number] = part
+	received := 1
+
+	buf := make([]byte, maxDatagram)
+	for received < part.total {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		next, err := c.parseSplit(append([]byte(nil), buf[:n]...))
+		if err != nil {
+			return nil, err
+		}
+		if next.id != part.id || next.total != part.total {
+			continue
+		}
+		if parts[next.number] == nil {
+			received++
+		}
+		parts[next.number] = next
+	}
+
+	var data []byte
+	for _, p := range parts {
+		data = append(data, p.payload...)
+	}
+
+	if parts[0].compress {
+		if data, err = decompress(data, parts[0].size, parts[0].crc); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(data) < 5 || int32(binary.LittleEndian.Uint32(data)) != packetSimple {
+		return nil, fmt.Errorf("%w: bad header of split response", ErrMalformed)
+	}
+
+	return data[4:], nil
+}
+
+// parseSplit parses the split packet in Source or GoldSrc format
+func (c *Client) parseSplit(packet []byte) (*splitPacket, error) {
+	r := &reader{data: packet}
+	if int32(r.uint32()) != packetSplit {
+		return nil, fmt.Errorf("%w: expected split packet", ErrMalformed)
+	}
+
+	p := &splitPacket{id: r.uint32()}
+	if c.goldSrc {
+		b := r.byte()
+		p.number, p.total = int(b>>4), int(b&0x0F)
+	} else {
+		p.total, p.number = int(r.byte()), int(r.byte())
+		r.uint16() // maximum packet size
+		p.compress = p.id&compressedSplitFlag != 0
+		if p.compress && p.number == 0 {
+			p.size, p.crc = r.uint32(), r.uint32()
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if p.total == 0 || p.number >= p.total {
+		return nil, fmt.Errorf("%w: split packet %d of %d", ErrMalformed, p.number, p.total)
+	}
+
+	p.payload = r.rest()
+	return p, nil
+}
+
+// decompress decompresses bzip2 data and checks the size and CRC32,
+// sizes over maxDecompressed are rejected
+func decompress(data []byte, size, crc uint32) ([]byte, error) {
+	if size > maxDecompressed {
+		return nil, fmt.Errorf("%w: decompressed size %d exceeds %d", ErrMalformed, size, maxDecompressed)
+	}
+
+	result, err := io.ReadAll(io.LimitReader(bzip2.NewReader(bytes.NewReader(data)), int64(size)+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	if uint32(len(result)) != size {
+		return nil, fmt.Errorf("%w: decompressed size %d, expected %d", ErrMalformed, len(result), size)
+	}
+	if crc32.ChecksumIEEE(result) != crc {
+		return nil, fmt.Errorf("%w: CRC32 mismatch of decompressed data", ErrMalformed)
+	}
+
+	return result, nil
+}
+
+// reader reads little-endian values from the packet, the first error is kept in err
+type reader struct {
+	err  error
+	data []byte
+	pos  int
+}
+
+// next returns n bytes or nil if data is too short
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if r.pos+n > len(r.data) {
+		r.err = fmt.Errorf("%w: unexpected end of data at %d", ErrMalformed, r.pos)
+		return nil
+	}
+
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+// byte reads a byte
+func (r *reader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// uint16 reads a little-endian uint16
+func (r *reader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+// uint32 reads a little-endian uint32
+func (r *reader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// uint64 reads a little-endian uint64
+func (r *reader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// float32 reads a little-endian float32
+func (r *reader) float32() float32 {
+	return math.Float32frombits(r.uint32())
+}
+
+// string reads the null-terminated string
+func (r *reader) string() string {
+	if r.err != nil {
+		return ""
+	}
+
+	i := bytes.IndexByte(r.data[r.pos:], 0)
+	if i < 0 {
+		r.err = fmt.Errorf("%w: unterminated string at %d", ErrMalformed, r.pos)
+		return ""
+	}
+
+	s := string(r.data[r.pos : r.pos+i])
+	r.pos += i + 1
+	return s
+}
+
+// shortString reads the string prefixed with a length byte
+func (r *reader) shortString() string {
+	return string(r.next(int(r.byte())))
+}
+
+// more reports whether there is unread data
+func (r *reader) more() bool {
+	return r.err == nil && r.pos < len(r.data)
+}
+
+// rest returns unread data
+func (r *reader) rest() []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	b := r.data[r.pos:]
+	r.pos = len(r.data)
+	return b
+}
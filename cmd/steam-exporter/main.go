@@ -0,0 +1,132 @@
+/*
+Command steam-exporter exposes Steam server list metrics for Prometheus.
+
+Targets are given as name=filter pairs with filters in the master server syntax,
+the API key is read from the STEAM_API_KEY environment variable:
+
+	STEAM_API_KEY=... steam-exporter \
+		-target 'dayz=\appid\221100' \
+		-target 'dayz_official=\appid\221100\gametype\battleye\nor\1\gametype\external' \
+		-threshold 40 -interval 2m -listen :9100
+
+Metrics are served on /metrics.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/woozymasta/steam/exporter"
+	"github.com/woozymasta/steam/serverlist"
+)
+
+// targetFlags collects repeated -target flags
+type targetFlags []exporter.Target
+
+// String implements flag.Value.
+func (t *targetFlags) String() string {
+	names := make([]string, 0, len(*t))
+	for _, target := range *t {
+		names = append(names, target.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements flag.Value parsing the name=filter pair.
+func (t *targetFlags) Set(value string) error {
+	name, filterString, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("target %q is not in name=filter form", value)
+	}
+	for _, target := range *t {
+		if target.Name == name {
+			return fmt.Errorf("target %q is repeated", name)
+		}
+	}
+
+	filter, err := serverlist.ParseFilter(filterString)
+	if err != nil {
+		return err
+	}
+
+	*t = append(*t, exporter.Target{Name: name, Filter: filter})
+	return nil
+}
+
+func main() {
+	var targets targetFlags
+	flag.Var(&targets, "target", "target as name=filter, may be repeated")
+	listen := flag.String("listen", ":9100", "address to serve metrics on")
+	interval := flag.Duration("interval", exporter.DefaultInterval, "interval between requests of the server lists")
+	threshold := flag.Float64("threshold", 0, "threshold of servers in percent to select the latest version")
+	debug := flag.Bool("debug", false, "enable debug logging")
+	flag.Parse()
+
+	level := slog.LevelInfo
+	if *debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	if err := run(logger, targets, *listen, *interval, *threshold); err != nil {
+		logger.Error("exporter failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run serves metrics until interrupted
+func run(logger *slog.Logger, targets []exporter.Target, listen string, interval time.Duration, threshold float64) error {
+	key := os.Getenv("STEAM_API_KEY")
+	if key == "" {
+		return errors.New("STEAM_API_KEY environment variable is not set")
+	}
+	if len(targets) == 0 {
+		return errors.New("no targets, use -target name=filter")
+	}
+	for i := range targets {
+		targets[i].Threshold = threshold
+	}
+
+	query := serverlist.New(key)
+	query.SetLogger(logger)
+
+	collector, err := exporter.New(query, targets...)
+	if err != nil {
+		return err
+	}
+	collector.SetInterval(interval)
+	collector.SetLogger(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() { _ = collector.Run(ctx) }()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", collector)
+	server := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	logger.Info("serving metrics", "addr", listen, "targets", len(targets))
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+/*
+Package exporter exposes metrics of Steam server lists in the Prometheus text exposition format.
+
+Collector periodically runs the configured serverlist filters (targets) and keeps gauges of
+the server count and players by application, version, map, region and OS, and the version
+selected by latest.FindVersion. The metrics are written by Collector.WriteTo or served over HTTP,
+Collector implements http.Handler. No Prometheus client library is required.
+
+# Example usage:
+
+	package main
+
+	import (
+		"context"
+		"log"
+		"net/http"
+		"os"
+
+		"github.com/woozymasta/steam/exporter"
+		"github.com/woozymasta/steam/serverlist"
+		"github.com/woozymasta/steam/utils/appid"
+	)
+
+	func main() {
+		collector, err := exporter.New(serverlist.New(os.Getenv("STEAM_API_KEY")),
+			exporter.Target{Name: "dayz", Filter: (&serverlist.Filter{}).AppID(appid.DayZ), Threshold: 40},
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go func() {
+			if err := collector.Run(context.Background()); err != nil {
+				log.Fatal(err)
+			}
+		}()
+
+		http.Handle("/metrics", collector)
+		log.Fatal(http.ListenAndServe(":9100", nil))
+	}
+*/
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/utils/latest"
+	"github.com/woozymasta/steam/utils/webapi"
+)
+
+// DefaultInterval defines the default interval between collections of Run.
+const DefaultInterval = time.Minute
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// ErrDuplicateTarget is returned by New when several targets have the same name.
+var ErrDuplicateTarget = errors.New("duplicate target name")
+
+// Target is a named server list filter collected by Collector.
+type Target struct {
+	Filter    *serverlist.Filter // Filter of servers
+	Name      string             // Name of the target used as the "target" label
+	Threshold float64            // Threshold of latest.FindVersion in percent (0..99)
+}
+
+// allLister is a lister bypassing the limit of servers in a single response, e.g. serverlist.SteamQuery
+type allLister interface {
+	GetAllContext(ctx context.Context, filter *serverlist.Filter) (serverlist.Servers, error)
+}
+
+// limiter is a lister with the limit of servers in a single response
+type limiter interface {
+	Limit() int
+}
+
+// Collector collects metrics of the targets.
+type Collector struct {
+	lister   serverlist.Lister
+	logger   *slog.Logger
+	results  map[string]*result
+	targets  []Target
+	interval time.Duration
+	mu       sync.RWMutex
+}
+
+// result is the last collection of a target
+type result struct {
+	time      time.Time
+	latest    string
+	counts    [dimensionCount]map[string]*count
+	duration  time.Duration
+	success   bool
+	truncated bool
+}
+
+// count is the number of servers and players
+type count struct {
+	servers int
+	players int
+}
+
+// New creates a new Collector of the targets using the lister, e.g. serverlist.SteamQuery.
+// The lister with GetAllContext (serverlist.SteamQuery) is queried with it to bypass the limit of servers.
+// Names of the targets must be unique, as they are the "target" label, otherwise ErrDuplicateTarget is returned.
+func New(lister serverlist.Lister, targets ...Target) (*Collector, error) {
+	names := make(map[string]struct{}, len(targets))
+	for _, target := range targets {
+		if _, ok := names[target.Name]; ok {
+			return nil, fmt.Errorf("%w %q", ErrDuplicateTarget, target.Name)
+		}
+		names[target.Name] = struct{}{}
+	}
+
+	return &Collector{
+		lister:   lister,
+		targets:  targets,
+		results:  make(map[string]*result, len(targets)),
+		interval: DefaultInterval,
+	}, nil
+}
+
+// SetInterval sets the interval between collections of Run.
+func (c *Collector) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	c.interval = interval
+}
+
+// SetLogger sets the logger for diagnostic messages, nothing is logged by default.
+func (c *Collector) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// Run collects the targets immediately and then on every interval until the context is done.
+// Failed collections are logged and reported with the steam_scrape_success metric.
+func (c *Collector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Collect(ctx); err != nil && ctx.Err() == nil {
+			webapi.Logger(c.logger).Warn("failed to collect server list metrics", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect requests servers of all targets once and updates the metrics.
+// Metrics of a failed target keep the previous values with steam_scrape_success set to 0.
+// Metrics of a truncated server list are updated with steam_scrape_truncated set to 1
+// and steam_scrape_success set to 0, serverlist.ErrTruncated is returned for the target.
+func (c *Collector) Collect(ctx context.Context) error {
+	var errs []error
+	for _, target := range c.targets {
+		if err := c.collect(ctx, target); err != nil {
+			errs = append(errs, fmt.Errorf("target %s: %w", target.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// collect requests servers of the target and stores the result
+func (c *Collector) collect(ctx context.Context, target Target) error {
+	start := time.Now()
+	servers, err := c.list(ctx, target.Filter)
+	duration := time.Since(start)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	truncated := errors.Is(err, serverlist.ErrTruncated)
+	if err != nil && !truncated {
+		r := c.results[target.Name]
+		if r == nil {
+			r = &result{}
+			c.results[target.Name] = r
+		}
+		r.success = false
+		r.duration = duration
+		r.time = start
+		return err
+	}
+
+	r := &result{time: start, duration: duration, success: !truncated, truncated: truncated}
+	for i := range r.counts {
+		r.counts[i] = make(map[string]*count)
+	}
+	for i := range servers {
+		s := &servers[i]
+		values := [dimensionCount]string{
+			dimensionApp:     strconv.FormatUint(s.Appid, 10),
+			dimensionVersion: s.Version,
+			dimensionMap:     s.Map,
+			dimensionRegion:  s.Region.String(),
+			dimensionOS:      s.OS.String(),
+		}
+		for d, value := range values {
+			cnt := r.counts[d][value]
+			if cnt == nil {
+				cnt = &count{}
+				r.counts[d][value] = cnt
+			}
+			cnt.servers++
+			cnt.players += int(s.Players)
+		}
+	}
+
+	if len(servers) > 0 {
+		var lerr error
+		if r.latest, lerr = latest.FindVersion(servers.GetVersionMap(), target.Threshold); lerr != nil {
+			webapi.Logger(c.logger).Warn("failed to find latest version", "target", target.Name, "error", lerr)
+		}
+	}
+
+	c.results[target.Name] = r
+	webapi.Logger(c.logger).Debug("server list metrics collected",
+		"target", target.Name, "servers", len(servers), "latest", r.latest, "truncated", truncated, "duration", duration)
+
+	return err
+}
+
+// list requests servers with the filter, the result reaching the limit of the lister is reported with
+// serverlist.ErrTruncated as servers beyond the limit are missing
+func (c *Collector) list(ctx context.Context, filter *serverlist.Filter) (serverlist.Servers, error) {
+	if all, ok := c.lister.(allLister); ok {
+		return all.GetAllContext(ctx, filter)
+	}
+
+	servers, err := c.lister.GetContext(ctx, filter)
+	if l, ok := c.lister.(limiter); ok && err == nil && l.Limit() > 0 && len(servers) >= l.Limit() {
+		return servers, serverlist.ErrTruncated
+	}
+
+	return servers, err
+}
+
+// ServeHTTP implements http.Handler writing the metrics in the text exposition format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	if _, err := c.WriteTo(w); err != nil {
+		webapi.Logger(c.logger).Warn("failed to write metrics", "error", err)
+	}
+}
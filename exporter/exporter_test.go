@@ -0,0 +1,204 @@
+package exporter_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/woozymasta/steam/exporter"
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/steamtest"
+)
+
+func newCollector(t *testing.T) (*exporter.Collector, *steamtest.Server) {
+	t.Helper()
+
+	srv := steamtest.New()
+	t.Cleanup(srv.Close)
+	srv.AddServers(
+		serverlist.Server{Addr: "10.0.0.1:27016", SteamID: 1, Appid: 221100, Version: "1.26.1", Map: "chernarusplus",
+			Region: serverlist.RegionEurope, OS: serverlist.OSWindows, Players: 10},
+		serverlist.Server{Addr: "10.0.0.1:27017", SteamID: 2, Appid: 221100, Version: "1.26.1", Map: "enoch",
+			Region: serverlist.RegionEurope, OS: serverlist.OSLinux, Players: 5},
+		serverlist.Server{Addr: "10.0.0.2:27016", SteamID: 3, Appid: 221100, Version: "1.25.9", Map: `odd"map`,
+			Region: serverlist.RegionUSEast, OS: serverlist.OSWindows, Players: 1},
+		serverlist.Server{Addr: "10.0.0.3:27015", SteamID: 4, Appid: 730, Version: "1.40", Map: "de_dust2", Players: 20},
+	)
+
+	query := serverlist.New(steamtest.Key)
+	query.SetClient(srv.Client())
+
+	collector, err := exporter.New(query,
+		exporter.Target{Name: "dayz", Filter: (&serverlist.Filter{}).AppID(221100), Threshold: 50},
+		exporter.Target{Name: "cs2", Filter: (&serverlist.Filter{}).AppID(730)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return collector, srv
+}
+
+func TestCollector(t *testing.T) {
+	collector, _ := newCollector(t)
+	if err := collector.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	n, err := collector.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo() = %d, %v", n, err)
+	}
+	out := buf.String()
+
+	for _, line := range []string{
+		"# TYPE steam_servers gauge",
+		`steam_servers{target="dayz",appid="221100"} 3`,
+		`steam_players{target="dayz",appid="221100"} 16`,
+		`steam_servers{target="cs2",appid="730"} 1`,
+		`steam_servers_by_version{target="dayz",version="1.26.1"} 2`,
+		`steam_players_by_map{target="dayz",map="odd\"map"} 1`,
+		`steam_servers_by_region{target="dayz",region="Europe"} 2`,
+		`steam_players_by_os{target="dayz",os="Windows"} 11`,
+		`steam_latest_version_info{target="dayz",version="1.26.1"} 1`,
+		`steam_scrape_success{target="cs2"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("metrics have no line %s", line)
+		}
+	}
+
+	if strings.Index(out, `target="cs2"`) > strings.Index(out, `target="dayz"`) {
+		t.Error("targets are not sorted")
+	}
+	if t.Failed() {
+		t.Log(out)
+	}
+}
+
+func TestCollectorFailure(t *testing.T) {
+	collector, srv := newCollector(t)
+	if err := collector.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.FailNext(http.StatusInternalServerError, 1)
+	if err := collector.Collect(context.Background()); err == nil || !strings.Contains(err.Error(), "target dayz") {
+		t.Fatalf("Collect() = %v, expected error of dayz target", err)
+	}
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != exporter.ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	out := rec.Body.String()
+	for _, line := range []string{
+		`steam_scrape_success{target="dayz"} 0`,
+		`steam_scrape_success{target="cs2"} 1`,
+		`steam_servers{target="dayz",appid="221100"} 3`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("metrics have no line %s", line)
+		}
+	}
+}
+
+func TestCollectorTruncated(t *testing.T) {
+	srv := steamtest.New()
+	t.Cleanup(srv.Close)
+	srv.AddServers(
+		serverlist.Server{Addr: "10.0.0.1:27016", SteamID: 1, Appid: 221100, Map: "chernarusplus", Region: serverlist.RegionEurope},
+		serverlist.Server{Addr: "10.0.0.2:27016", SteamID: 2, Appid: 221100, Map: "enoch", Region: serverlist.RegionUSEast},
+		serverlist.Server{Addr: "10.0.0.3:27016", SteamID: 3, Appid: 221100, Map: "sakhal", Region: serverlist.RegionAsia},
+	)
+	for i := 0; i < 3; i++ {
+		srv.AddServers(serverlist.Server{Addr: fmt.Sprintf("10.0.1.1:%d", 27016+i), Appid: 730, Map: "de_dust2"})
+	}
+
+	query := serverlist.New(steamtest.Key)
+	query.SetClient(srv.Client())
+	query.SetLimit(2)
+
+	collector, err := exporter.New(query,
+		exporter.Target{Name: "dayz", Filter: (&serverlist.Filter{}).AppID(221100)},
+		exporter.Target{Name: "cs2", Filter: (&serverlist.Filter{}).AppID(730)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = collector.Collect(context.Background())
+	if !errors.Is(err, serverlist.ErrTruncated) || !strings.Contains(err.Error(), "target cs2") ||
+		strings.Contains(err.Error(), "target dayz") {
+		t.Fatalf("Collect() = %v, expected ErrTruncated of cs2 target", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := collector.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, line := range []string{
+		`steam_servers{target="dayz",appid="221100"} 3`,
+		`steam_scrape_success{target="dayz"} 1`,
+		`steam_scrape_truncated{target="dayz"} 0`,
+		`steam_servers{target="cs2",appid="730"} 2`,
+		`steam_scrape_success{target="cs2"} 0`,
+		`steam_scrape_truncated{target="cs2"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("metrics have no line %s", line)
+		}
+	}
+	if t.Failed() {
+		t.Log(out)
+	}
+}
+
+func TestCollectorTruncatedMaster(t *testing.T) {
+	master := steamtest.NewMaster()
+	t.Cleanup(func() { _ = master.Close() })
+	master.AddServers(
+		serverlist.Server{Addr: "10.0.0.1:27016", Appid: 221100},
+		serverlist.Server{Addr: "10.0.0.2:27016", Appid: 221100},
+	)
+
+	query := serverlist.NewMaster()
+	query.SetAddr(master.Addr())
+	query.SetLimit(2)
+
+	collector, err := exporter.New(query, exporter.Target{Name: "dayz", Filter: (&serverlist.Filter{}).AppID(221100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := collector.Collect(context.Background()); !errors.Is(err, serverlist.ErrTruncated) {
+		t.Fatalf("Collect() = %v, expected ErrTruncated", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := collector.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `steam_scrape_truncated{target="dayz"} 1`+"\n") ||
+		!strings.Contains(out, `steam_servers{target="dayz",appid="0"} 2`+"\n") {
+		t.Errorf("unexpected metrics\n%s", out)
+	}
+}
+
+func TestDuplicateTarget(t *testing.T) {
+	_, err := exporter.New(serverlist.New(steamtest.Key),
+		exporter.Target{Name: "dayz", Filter: (&serverlist.Filter{}).AppID(221100)},
+		exporter.Target{Name: "dayz", Filter: (&serverlist.Filter{}).AppID(221100).Dedicated()},
+	)
+	if !errors.Is(err, exporter.ErrDuplicateTarget) {
+		t.Errorf("New() = %v, expected ErrDuplicateTarget", err)
+	}
+}
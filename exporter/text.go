@@ -0,0 +1,162 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dimensions of server and player counts
+const (
+	dimensionApp = iota
+	dimensionVersion
+	dimensionMap
+	dimensionRegion
+	dimensionOS
+	dimensionCount
+)
+
+// dimensions are label names and metric name suffixes of dimensions
+var dimensions = [dimensionCount]struct{ label, suffix string }{
+	dimensionApp:     {"appid", ""},
+	dimensionVersion: {"version", "_by_version"},
+	dimensionMap:     {"map", "_by_map"},
+	dimensionRegion:  {"region", "_by_region"},
+	dimensionOS:      {"os", "_by_os"},
+}
+
+// labelEscaper escapes label values of the text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writer writes the metrics in the text exposition format and counts written bytes
+type writer struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+// WriteTo implements io.WriterTo writing the metrics of the last collection in the text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.results))
+	for name := range c.results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := &writer{w: bufio.NewWriter(w)}
+
+	for d, dim := range dimensions {
+		for _, players := range []bool{false, true} {
+			name, help := "steam_servers"+dim.suffix, "Number of servers by "+dim.label+"."
+			if players {
+				name, help = "steam_players"+dim.suffix, "Number of players by "+dim.label+"."
+			}
+			tw.header(name, help)
+
+			for _, target := range names {
+				r := c.results[target]
+				if r.counts[d] == nil {
+					continue
+				}
+				values := make([]string, 0, len(r.counts[d]))
+				for value := range r.counts[d] {
+					values = append(values, value)
+				}
+				sort.Strings(values)
+
+				for _, value := range values {
+					cnt := r.counts[d][value]
+					v := cnt.servers
+					if players {
+						v = cnt.players
+					}
+					tw.sample(name, float64(v), "target", target, dim.label, value)
+				}
+			}
+		}
+	}
+
+	tw.header("steam_latest_version_info", "Version selected by latest.FindVersion, the value is always 1.")
+	for _, target := range names {
+		if v := c.results[target].latest; v != "" {
+			tw.sample("steam_latest_version_info", 1, "target", target, "version", v)
+		}
+	}
+
+	tw.header("steam_scrape_success", "Whether the last request of the server list succeeded.")
+	for _, target := range names {
+		success := 0.0
+		if c.results[target].success {
+			success = 1
+		}
+		tw.sample("steam_scrape_success", success, "target", target)
+	}
+
+	tw.header("steam_scrape_truncated", "Whether the last server list reached the limit of servers and is incomplete.")
+	for _, target := range names {
+		truncated := 0.0
+		if c.results[target].truncated {
+			truncated = 1
+		}
+		tw.sample("steam_scrape_truncated", truncated, "target", target)
+	}
+
+	tw.header("steam_scrape_duration_seconds", "Duration of the last request of the server list.")
+	for _, target := range names {
+		tw.sample("steam_scrape_duration_seconds", c.results[target].duration.Seconds(), "target", target)
+	}
+
+	tw.header("steam_scrape_timestamp_seconds", "Unix time of the last request of the server list.")
+	for _, target := range names {
+		t := c.results[target].time
+		tw.sample("steam_scrape_timestamp_seconds", float64(t.UnixNano())/1e9, "target", target)
+	}
+
+	if tw.err == nil {
+		tw.err = tw.w.Flush()
+	}
+
+	return tw.n, tw.err
+}
+
+// header writes HELP and TYPE lines of the gauge
+func (tw *writer) header(name, help string) {
+	tw.write("# HELP " + name + " " + help + "\n# TYPE " + name + " gauge\n")
+}
+
+// sample writes the sample with label name and value pairs
+func (tw *writer) sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(labels[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteString("} ")
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteByte('\n')
+
+	tw.write(b.String())
+}
+
+// write writes the string keeping the first error
+func (tw *writer) write(s string) {
+	if tw.err != nil {
+		return
+	}
+
+	n, err := tw.w.WriteString(s)
+	tw.n += int64(n)
+	tw.err = err
+}
@@ -0,0 +1,107 @@
+package filedetails
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AppIDPolicy defines how items with ConsumerAppID not matching Query.AppID are handled.
+type AppIDPolicy int
+
+const (
+	// AppIDMismatchFail returns *AppIDMismatchError without details of chunks with mismatched items (default).
+	AppIDMismatchFail AppIDPolicy = iota
+	// AppIDMismatchDrop removes mismatched items from the result.
+	AppIDMismatchDrop
+	// AppIDMismatchFlag keeps mismatched items in the result with FileDetail.AppIDMismatch set.
+	AppIDMismatchFlag
+)
+
+// AppIDMismatch describes an item published for another application.
+type AppIDMismatch struct {
+	PublishedFileID uint64 // The unique ID of the published file.
+	ConsumerAppID   uint64 // App ID of the consumer.
+	CreatorAppID    uint64 // App ID of the creator.
+}
+
+// AppIDMismatchError is returned when items have ConsumerAppID not matching Query.AppID.
+// Use errors.As to get the list of all offending items.
+type AppIDMismatchError struct {
+	Items []AppIDMismatch // All mismatched items
+	AppID uint64          // Expected application ID
+}
+
+// Error implements the error interface.
+func (e *AppIDMismatchError) Error() string {
+	items := make([]string, len(e.Items))
+	for i, m := range e.Items {
+		items[i] = fmt.Sprintf("%d (appid %d)", m.PublishedFileID, m.ConsumerAppID)
+	}
+
+	return fmt.Sprintf("%d items not match appid %d: %s", len(e.Items), e.AppID, strings.Join(items, ", "))
+}
+
+// IDs returns published file IDs of all mismatched items.
+func (e *AppIDMismatchError) IDs() []uint64 {
+	ids := make([]uint64, len(e.Items))
+	for i, m := range e.Items {
+		ids[i] = m.PublishedFileID
+	}
+
+	return ids
+}
+
+// merge appends items of other error, returns new error if e is nil
+func (e *AppIDMismatchError) merge(err error) *AppIDMismatchError {
+	other, ok := err.(*AppIDMismatchError)
+	if !ok {
+		return e
+	}
+	if e == nil {
+		return &AppIDMismatchError{AppID: other.AppID, Items: append([]AppIDMismatch(nil), other.Items...)}
+	}
+
+	e.Items = append(e.Items, other.Items...)
+	return e
+}
+
+// checkAppID validates AppID == ConsumerAppID and applies the mismatch policy.
+// Items without ConsumerAppID (e.g. not found) are not checked.
+func (q *Query) checkAppID(details []FileDetail) ([]FileDetail, error) {
+	if q.AppID == 0 {
+		return details, nil
+	}
+
+	var mismatch *AppIDMismatchError
+	result := details[:0:0]
+	for _, f := range details {
+		if f.ConsumerAppID == 0 || f.ConsumerAppID == q.AppID {
+			result = append(result, f)
+			continue
+		}
+
+		if mismatch == nil {
+			mismatch = &AppIDMismatchError{AppID: q.AppID}
+		}
+		mismatch.Items = append(mismatch.Items, AppIDMismatch{
+			PublishedFileID: f.PublishedFileID,
+			ConsumerAppID:   f.ConsumerAppID,
+			CreatorAppID:    f.CreatorAppID,
+		})
+
+		switch q.appIDPolicy {
+		case AppIDMismatchDrop:
+		case AppIDMismatchFlag:
+			f.AppIDMismatch = true
+			result = append(result, f)
+		default:
+			result = append(result, f)
+		}
+	}
+
+	if mismatch == nil {
+		return details, nil
+	}
+
+	return result, mismatch
+}
@@ -0,0 +1,67 @@
+package filedetails_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/woozymasta/steam/filedetails"
+	"github.com/woozymasta/steam/steamtest"
+)
+
+func TestAppIDPolicy(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+	srv.AddFiles(
+		filedetails.FileDetail{PublishedFileID: 1, ConsumerAppID: 221100, Title: "DayZ mod"},
+		filedetails.FileDetail{PublishedFileID: 2, ConsumerAppID: 107410, Title: "Arma 3 mod"},
+		filedetails.FileDetail{PublishedFileID: 3, ConsumerAppID: 221100, Title: "DayZ mod"},
+		filedetails.FileDetail{PublishedFileID: 4, ConsumerAppID: 107410, Title: "Arma 3 mod"},
+	)
+
+	query := filedetails.New([]uint64{1, 2, 3, 4, 5}, steamtest.Key)
+	query.SetClient(srv.Client())
+	query.SetAppID(221100)
+	query.SetChunkMax(2)
+
+	cases := []struct {
+		policy filedetails.AppIDPolicy
+		files  int
+		ids    int
+	}{
+		{filedetails.AppIDMismatchFail, 1, 2},
+		{filedetails.AppIDMismatchDrop, 3, 2},
+		{filedetails.AppIDMismatchFlag, 5, 2},
+	}
+
+	for _, c := range cases {
+		query.SetAppIDPolicy(c.policy)
+		files, err := query.Get()
+
+		var mismatch *filedetails.AppIDMismatchError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("policy %d: Get() returned %v, expected AppIDMismatchError", c.policy, err)
+		}
+		if len(files) != c.files {
+			t.Errorf("policy %d: Get() returned %d files, expected %d", c.policy, len(files), c.files)
+		}
+		if ids := mismatch.IDs(); len(ids) != c.ids || ids[0] != 2 {
+			t.Errorf("policy %d: mismatched IDs %v", c.policy, ids)
+		}
+
+		for _, f := range files {
+			if f.AppIDMismatch != (f.ConsumerAppID == 107410) {
+				t.Errorf("policy %d: item %d flag is %v", c.policy, f.PublishedFileID, f.AppIDMismatch)
+			}
+		}
+	}
+
+	for _, c := range cases {
+		query.SetAppIDPolicy(c.policy)
+		files, err := query.GetConcurrent()
+
+		var mismatch *filedetails.AppIDMismatchError
+		if !errors.As(err, &mismatch) || len(mismatch.Items) != c.ids || len(files) != c.files {
+			t.Errorf("policy %d: GetConcurrent() returned %d files, error %v", c.policy, len(files), err)
+		}
+	}
+}
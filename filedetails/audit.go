@@ -0,0 +1,211 @@
+package filedetails
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity is the severity of an audit Finding.
+type Severity int
+
+const (
+	SeverityInfo    Severity = iota // Informational finding
+	SeverityWarning                 // Item may cause problems
+	SeverityError                   // Item can't be used by players or server
+)
+
+// Visibility values of FileDetail.Visibility.
+const (
+	VisibilityPublic      = 0 // Visible to everyone
+	VisibilityFriendsOnly = 1 // Visible to friends of the creator only
+	VisibilityPrivate     = 2 // Visible to the creator only
+	VisibilityUnlisted    = 3 // Not listed, but available by link
+)
+
+// Codes of audit findings.
+const (
+	FindingResult          = "result"           // Result is not OK (e.g. item not found)
+	FindingBanned          = "banned"           // Item is banned
+	FindingVisibility      = "visibility"       // Item is not public
+	FindingNotSubscribable = "not_subscribable" // Item can't be subscribed
+	FindingAppID           = "appid"            // Item is published for another application
+	FindingInappropriate   = "inappropriate"    // Item may contain inappropriate content
+	FindingStale           = "stale"            // Item is not updated for a long time
+)
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for the Severity type.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for the Severity type.
+func (s *Severity) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "info":
+		*s = SeverityInfo
+	case "warning":
+		*s = SeverityWarning
+	case "error":
+		*s = SeverityError
+	default:
+		return fmt.Errorf("unknown severity %q", text)
+	}
+
+	return nil
+}
+
+// Finding is a problem of a workshop item found by Audit.
+type Finding struct {
+	Code            string   `json:"code"`            // Finding code, one of Finding* constants.
+	Message         string   `json:"message"`         // Human readable description.
+	Title           string   `json:"title"`           // The title of the file.
+	PublishedFileID uint64   `json:"publishedfileid"` // The unique ID of the published file.
+	Severity        Severity `json:"severity"`        // Severity of the finding.
+}
+
+// Findings is a list of audit findings.
+type Findings []Finding
+
+// Max returns the highest severity of the findings, SeverityInfo if there are no findings.
+func (f Findings) Max() Severity {
+	severity := SeverityInfo
+	for _, finding := range f {
+		if finding.Severity > severity {
+			severity = finding.Severity
+		}
+	}
+
+	return severity
+}
+
+// AtLeast returns findings with at least the given severity.
+func (f Findings) AtLeast(severity Severity) Findings {
+	var result Findings
+	for _, finding := range f {
+		if finding.Severity >= severity {
+			result = append(result, finding)
+		}
+	}
+
+	return result
+}
+
+// Auditor checks workshop items for problems on a game server.
+type Auditor struct {
+	now             func() time.Time
+	AppID           uint64 // Expected ConsumerAppID, 0 disables the check.
+	StaleMonths     int    // Items not updated for this count of months are reported, 0 disables the check.
+	IgnoreSubscribe bool   // Don't check CanSubscribe, it is never checked for items with RemoteStorage set.
+}
+
+/*
+Audit checks workshop items with the default Auditor: without AppID check
+and with items not updated for 12 months reported as stale.
+
+Parameters:
+  - details: Workshop items, e.g. the server mod list.
+
+Returns:
+  - Findings in the order of details.
+*/
+func Audit(details []FileDetail) Findings {
+	a := Auditor{StaleMonths: 12}
+	return a.Audit(details)
+}
+
+/*
+Audit checks workshop items for problems:
+  - error: Result is not OK, item is banned, private or friends only, can't be subscribed
+    or has ConsumerAppID not matching AppID
+  - warning: item is unlisted, may contain inappropriate content or is not updated for StaleMonths
+
+Items with not OK Result have no other data, so they get a single finding.
+EndpointRemoteStorage doesn't return CanSubscribe, so it is not checked for items with RemoteStorage set,
+items without the update time are not checked for StaleMonths.
+
+Parameters:
+  - details: Workshop items, e.g. the server mod list.
+
+Returns:
+  - Findings in the order of details.
+*/
+func (a *Auditor) Audit(details []FileDetail) Findings {
+	now := time.Now()
+	if a.now != nil {
+		now = a.now()
+	}
+
+	var findings Findings
+	for _, fd := range details {
+		add := func(severity Severity, code, format string, args ...any) {
+			findings = append(findings, Finding{
+				PublishedFileID: fd.PublishedFileID,
+				Title:           fd.Title,
+				Severity:        severity,
+				Code:            code,
+				Message:         fmt.Sprintf(format, args...),
+			})
+		}
+
+		if fd.Result != 1 {
+			add(SeverityError, FindingResult, "item is not available, result %d", fd.Result)
+			continue
+		}
+
+		if fd.Banned {
+			if fd.BanReason != "" {
+				add(SeverityError, FindingBanned, "item is banned: %s", fd.BanReason)
+			} else {
+				add(SeverityError, FindingBanned, "item is banned")
+			}
+		}
+
+		switch fd.Visibility {
+		case VisibilityPublic:
+		case VisibilityUnlisted:
+			add(SeverityWarning, FindingVisibility, "item is unlisted")
+		case VisibilityFriendsOnly:
+			add(SeverityError, FindingVisibility, "item is visible to friends only")
+		case VisibilityPrivate:
+			add(SeverityError, FindingVisibility, "item is private")
+		default:
+			add(SeverityWarning, FindingVisibility, "item has unknown visibility %d", fd.Visibility)
+		}
+
+		if !fd.CanSubscribe && !fd.RemoteStorage && !a.IgnoreSubscribe {
+			add(SeverityError, FindingNotSubscribable, "item can't be subscribed")
+		}
+
+		if a.AppID != 0 && fd.ConsumerAppID != a.AppID {
+			add(SeverityError, FindingAppID, "item is published for appid %d, expected %d", fd.ConsumerAppID, a.AppID)
+		}
+
+		if fd.MaybeInappropriateSex {
+			add(SeverityWarning, FindingInappropriate, "item may contain sexual content")
+		}
+		if fd.MaybeInappropriateViolence {
+			add(SeverityWarning, FindingInappropriate, "item may contain violent content")
+		}
+
+		if a.StaleMonths > 0 && known(fd.TimeUpdated) && fd.TimeUpdated.Before(now.AddDate(0, -a.StaleMonths, 0)) {
+			add(SeverityWarning, FindingStale, "item is not updated since %s", fd.TimeUpdated.Format(time.DateOnly))
+		}
+	}
+
+	return findings
+}
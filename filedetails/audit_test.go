@@ -0,0 +1,77 @@
+package filedetails
+
+import (
+	"testing"
+	"time"
+
+	json "github.com/json-iterator/go"
+)
+
+func TestAudit(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	ok := FileDetail{Result: 1, ConsumerAppID: 221100, CanSubscribe: true, TimeUpdated: now}
+
+	item := func(id uint64, change func(*FileDetail)) FileDetail {
+		fd := ok
+		fd.PublishedFileID = id
+		change(&fd)
+		return fd
+	}
+
+	details := []FileDetail{
+		item(1, func(*FileDetail) {}),
+		item(2, func(fd *FileDetail) { fd.Result = 9 }),
+		item(3, func(fd *FileDetail) { fd.Banned, fd.BanReason = true, "copyright" }),
+		item(4, func(fd *FileDetail) { fd.Visibility = VisibilityPrivate }),
+		item(5, func(fd *FileDetail) { fd.Visibility = VisibilityUnlisted }),
+		item(6, func(fd *FileDetail) { fd.CanSubscribe = false }),
+		item(7, func(fd *FileDetail) { fd.ConsumerAppID = 107410 }),
+		item(8, func(fd *FileDetail) { fd.MaybeInappropriateViolence = true }),
+		item(9, func(fd *FileDetail) { fd.TimeUpdated = now.AddDate(-1, -1, 0) }),
+	}
+
+	a := &Auditor{AppID: 221100, StaleMonths: 6, now: func() time.Time { return now }}
+	findings := a.Audit(details)
+
+	want := []struct {
+		code     string
+		id       uint64
+		severity Severity
+	}{
+		{FindingResult, 2, SeverityError},
+		{FindingBanned, 3, SeverityError},
+		{FindingVisibility, 4, SeverityError},
+		{FindingVisibility, 5, SeverityWarning},
+		{FindingNotSubscribable, 6, SeverityError},
+		{FindingAppID, 7, SeverityError},
+		{FindingInappropriate, 8, SeverityWarning},
+		{FindingStale, 9, SeverityWarning},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Audit() returned %d findings, expected %d: %+v", len(findings), len(want), findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.Code != w.code || f.PublishedFileID != w.id || f.Severity != w.severity || f.Message == "" {
+			t.Errorf("finding %d = %+v, expected %+v", i, f, w)
+		}
+	}
+
+	if findings.Max() != SeverityError || len(findings.AtLeast(SeverityError)) != 5 {
+		t.Errorf("Max() = %s, AtLeast(error) = %d", findings.Max(), len(findings.AtLeast(SeverityError)))
+	}
+
+	a.IgnoreSubscribe = true
+	if len(a.Audit(details[5:6])) != 0 {
+		t.Error("IgnoreSubscribe must disable the check")
+	}
+
+	data, err := json.Marshal(findings[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f Finding
+	if err := json.Unmarshal(data, &f); err != nil || f != findings[0] {
+		t.Errorf("JSON round trip %s: %+v, %v", data, f, err)
+	}
+}
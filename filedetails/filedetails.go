@@ -12,6 +12,9 @@ API Documentation:
 
 To obtain an API key, visit: [Steam Dev API Key]
 
+Without an API key requests are sent to the keyless [Steam API GetPublishedFileDetails v1] endpoint,
+which returns only part of the fields, see EndpointRemoteStorage for details.
+
 # Example usage:
 
 	package main
@@ -53,13 +56,16 @@ To obtain an API key, visit: [Steam Dev API Key]
 
 [Steam API GetDetails v1]: https://api.steampowered.com/IPublishedFileService/GetDetails/v1/
 [Steam API Reference by XPaw]: https://steamapi.xpaw.me/#IPublishedFileService/GetDetails
+[Steam API GetPublishedFileDetails v1]: https://steamapi.xpaw.me/#ISteamRemoteStorage/GetPublishedFileDetails
 [Steam Dev API Key]: https://steamcommunity.com/dev/apikey
 */
 package filedetails
 
 const (
-	baseURL         string = "https://api.steampowered.com/IPublishedFileService/GetDetails/v1/"
 	baseFileURL     string = "https://steamcommunity.com/sharedfiles/filedetails/?id="
 	defaultChunkMax        = 220
 	defaultConns           = 10
 )
+
+// baseURL is the IPublishedFileService/GetDetails endpoint, a variable to be replaced in tests
+var baseURL = "https://api.steampowered.com/IPublishedFileService/GetDetails/v1/"
@@ -1,19 +1,19 @@
-package filedetails
+package filedetails_test
 
 import (
 	"fmt"
 	"math/rand"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/woozymasta/steam/filedetails"
+	"github.com/woozymasta/steam/steamtest"
+	"github.com/woozymasta/steam/utils/webapi"
 )
 
 func TestGetMods(t *testing.T) {
-	key, ok := os.LookupEnv("STEAM_API_KEY")
-	if !ok {
-		t.Error("Steam API key must be pass in variable 'STEAM_API_KEY'")
-	}
-
 	ids := []uint64{
 		1559212036, // CF
 		2545327648, // Dabs Framework
@@ -21,7 +21,7 @@ func TestGetMods(t *testing.T) {
 		3213284654, // DayZ (screenshots)
 	}
 
-	query := New(ids, key)
+	query := testQuery(t, ids)
 	query.SetAppID(221100)
 
 	files, err := query.Get()
@@ -38,14 +38,9 @@ func TestGetMods(t *testing.T) {
 }
 
 func TestGetManyFiles(t *testing.T) {
-	key, ok := os.LookupEnv("STEAM_API_KEY")
-	if !ok {
-		t.Error("Steam API key must be pass in variable 'STEAM_API_KEY'")
-	}
-
 	count := 700
 	ids := randomIDs(count, 1500000000, 3500000000)
-	query := New(ids, key)
+	query := testQuery(t, ids)
 
 	files, err := query.Get()
 	if err != nil {
@@ -58,14 +53,9 @@ func TestGetManyFiles(t *testing.T) {
 }
 
 func TestGetManyFilesConcurrent(t *testing.T) {
-	key, ok := os.LookupEnv("STEAM_API_KEY")
-	if !ok {
-		t.Error("Steam API key must be pass in variable 'STEAM_API_KEY'")
-	}
-
 	count := 5000
 	ids := randomIDs(count, 1500000000, 3500000000)
-	query := New(ids, key)
+	query := testQuery(t, ids)
 	query.SetConcurrency(100)
 
 	files, err := query.GetConcurrent()
@@ -78,6 +68,104 @@ func TestGetManyFilesConcurrent(t *testing.T) {
 	}
 }
 
+// testQuery returns a query to the Steam API if STEAM_API_KEY is set,
+// otherwise to the steamtest fake server with the same items
+func TestSetChunkMax(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+
+	ids := []uint64{1, 2, 3, 4, 5, 6, 7}
+	query := filedetails.New(ids, steamtest.Key)
+	query.SetClient(srv.Client())
+	query.SetConcurrency(2)
+
+	cases := []struct {
+		chunkMax int
+		requests int
+	}{
+		{3, 3},
+		{1, 7},
+		{0, 1},
+		{-1, 1},
+	}
+
+	for _, c := range cases {
+		query.SetChunkMax(c.chunkMax)
+		for _, get := range []func() ([]filedetails.FileDetail, error){query.Get, query.GetConcurrent} {
+			srv.Reset()
+			files, err := get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != len(ids) || len(srv.Requests()) != c.requests {
+				t.Errorf("chunk max %d: %d files in %d requests, expected %d requests",
+					c.chunkMax, len(files), len(srv.Requests()), c.requests)
+			}
+		}
+	}
+}
+
+func TestChunkOptions(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+	srv.AddFiles(
+		filedetails.FileDetail{PublishedFileID: 1, ConsumerAppID: 221100, Tags: []filedetails.Tags{{Tag: "Mod"}}},
+		filedetails.FileDetail{PublishedFileID: 2, ConsumerAppID: 221100, Tags: []filedetails.Tags{{Tag: "Mod"}}},
+		filedetails.FileDetail{PublishedFileID: 3, ConsumerAppID: 221100, Tags: []filedetails.Tags{{Tag: "Map"}}},
+	)
+
+	var observed atomic.Int32
+	query := filedetails.New([]uint64{1, 2, 3}, steamtest.Key)
+	query.SetClient(srv.Client())
+	query.SetChunkMax(1)
+	query.SetObserver(webapi.ObserverFunc(func(webapi.RequestInfo) { observed.Add(1) }))
+	query.Language = "german"
+	query.IncludeTags = true
+
+	for _, get := range []func() ([]filedetails.FileDetail, error){query.Get, query.GetConcurrent} {
+		srv.Reset()
+		observed.Store(0)
+
+		files, err := get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if len(f.Tags) == 0 {
+				t.Errorf("item %d has no tags, IncludeTags is not forwarded to chunks", f.PublishedFileID)
+			}
+		}
+		for _, r := range srv.Requests() {
+			if r.Query.Get("language") != "german" || r.Query.Get("key") != steamtest.Key {
+				t.Errorf("chunk request query %v lost options", r.Query)
+			}
+		}
+		if n := observed.Load(); n != 3 || len(srv.Requests()) != 3 {
+			t.Errorf("observed %d of %d chunk requests, expected 3", n, len(srv.Requests()))
+		}
+	}
+}
+
+func testQuery(t *testing.T, ids []uint64) *filedetails.Query {
+	if key, ok := os.LookupEnv("STEAM_API_KEY"); ok {
+		return filedetails.New(ids, key)
+	}
+
+	srv := steamtest.New()
+	t.Cleanup(srv.Close)
+	srv.AddFiles(
+		filedetails.FileDetail{PublishedFileID: 1559212036, ConsumerAppID: 221100, Title: "Community Framework"},
+		filedetails.FileDetail{PublishedFileID: 2545327648, ConsumerAppID: 221100, Title: "Dabs Framework"},
+		filedetails.FileDetail{PublishedFileID: 2874283306, ConsumerAppID: 221100, Title: "DayZ launcher Linux"},
+		filedetails.FileDetail{PublishedFileID: 3213284654, ConsumerAppID: 221100, Title: "DayZ"},
+	)
+
+	query := filedetails.New(ids, steamtest.Key)
+	query.SetClient(srv.Client())
+
+	return query
+}
+
 func randomIDs(count int, min, max uint64) []uint64 {
 	if min > max {
 		return []uint64{min, max}
@@ -1,39 +1,49 @@
 package filedetails
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	json "github.com/json-iterator/go"
+	"github.com/woozymasta/steam/utils/webapi"
 )
 
 // Structure describing the parameters of a request to IPublishedFileService/GetDetails/v1/
 type Query struct {
-	key                       string   ``                                           // Access API key
-	Language                  string   `json:"language,omitempty"`                  // Specifies the localized text to return. Defaults to English. //* ELanguage
-	DesiredRevision           string   `json:"desired_revision,omitempty"`          // Return the data for the specified revision. //* EPublishedFileRevision
-	PublishedFileIDs          []uint64 `json:"publishedfileids"`                    // Set of published file Ids to retrieve details for.
-	concurrent                int      ``                                           // Max items per chunk (internal)
-	chunkMax                  int      ``                                           // Concurrent requests (internal)
-	AppID                     uint64   `json:"appid,omitempty"`                     // Application ID
-	ReturnPlaytimeStats       uint32   `json:"return_playtime_stats,omitempty"`     // Return playtime stats for the specified number of days before today.
-	IncludeTags               bool     `json:"includetags,omitempty"`               // If true, return tag information in the returned details.
-	IncludeAdditionalPreviews bool     `json:"includeadditionalpreviews,omitempty"` // If true, return preview information in the returned details.
-	IncludeChildren           bool     `json:"includechildren,omitempty"`           // If true, return children in the returned details.
-	IncludeKVTags             bool     `json:"includekvtags,omitempty"`             // If true, return key value tags in the returned details.
-	IncludeVotes              bool     `json:"includevotes,omitempty"`              // If true, return vote data in the returned details.
-	ShortDescription          bool     `json:"short_description,omitempty"`         // If true, return a short description instead of the full description.
-	IncludeForSaleData        bool     `json:"includeforsaledata,omitempty"`        // If true, return pricing data, if applicable.
-	IncludeMetadata           bool     `json:"includemetadata,omitempty"`           // If true, populate the metadata field.
-	StripDescriptionBBCode    bool     `json:"strip_description_bbcode,omitempty"`  // Strips BBCode from descriptions.
-	IncludeReactions          bool     `json:"includereactions,omitempty"`          // If true, then reactions to items will be returned.
-	AdminQuery                bool     `json:"admin_query,omitempty"`               // Admin tool is doing a query, return hidden items
+	observer                  webapi.Observer    ``                                           // Request observer (internal)
+	keys                      webapi.KeyProvider ``                                           // API key provider (internal)
+	client                    *http.Client       ``                                           // HTTP client (internal)
+	logger                    *slog.Logger       ``                                           // Logger (internal)
+	key                       string             ``                                           // Access API key
+	Language                  string             `json:"language,omitempty"`                  // Specifies the localized text to return. Defaults to English. //* ELanguage
+	DesiredRevision           string             `json:"desired_revision,omitempty"`          // Return the data for the specified revision. //* EPublishedFileRevision
+	PublishedFileIDs          []uint64           `json:"publishedfileids"`                    // Set of published file Ids to retrieve details for.
+	concurrent                int                ``                                           // Concurrent requests (internal)
+	appIDPolicy               AppIDPolicy        ``                                           // AppID mismatch policy (internal)
+	endpointMode              Endpoint           ``                                           // API endpoint (internal)
+	chunkMax                  int                ``                                           // Max items per chunk (internal)
+	AppID                     uint64             `json:"appid,omitempty"`                     // Application ID
+	ReturnPlaytimeStats       uint32             `json:"return_playtime_stats,omitempty"`     // Return playtime stats for the specified number of days before today.
+	IncludeTags               bool               `json:"includetags,omitempty"`               // If true, return tag information in the returned details.
+	IncludeAdditionalPreviews bool               `json:"includeadditionalpreviews,omitempty"` // If true, return preview information in the returned details.
+	IncludeChildren           bool               `json:"includechildren,omitempty"`           // If true, return children in the returned details.
+	IncludeKVTags             bool               `json:"includekvtags,omitempty"`             // If true, return key value tags in the returned details.
+	IncludeVotes              bool               `json:"includevotes,omitempty"`              // If true, return vote data in the returned details.
+	ShortDescription          bool               `json:"short_description,omitempty"`         // If true, return a short description instead of the full description.
+	IncludeForSaleData        bool               `json:"includeforsaledata,omitempty"`        // If true, return pricing data, if applicable.
+	IncludeMetadata           bool               `json:"includemetadata,omitempty"`           // If true, populate the metadata field.
+	StripDescriptionBBCode    bool               `json:"strip_description_bbcode,omitempty"`  // Strips BBCode from descriptions.
+	IncludeReactions          bool               `json:"includereactions,omitempty"`          // If true, then reactions to items will be returned.
+	AdminQuery                bool               `json:"admin_query,omitempty"`               // Admin tool is doing a query, return hidden items
 }
 
 /*
@@ -45,12 +55,15 @@ Parameters:
   - fileIDs: A slice of published file IDs to retrieve details for.
   - key: The API key for accessing the Steam API.
 
+If key is empty, requests are sent to the keyless ISteamRemoteStorage/GetPublishedFileDetails endpoint,
+see SetEndpoint for details.
+
 Returns:
-  - A pointer to a Query instance if key is non-empty and fileIDs is not empty.
+  - A pointer to a Query instance if fileIDs is not empty.
   - nil otherwise.
 */
 func New(fileIDs []uint64, key string) *Query {
-	if key == "" || len(fileIDs) == 0 {
+	if len(fileIDs) == 0 {
 		return nil
 	}
 
@@ -75,6 +88,47 @@ func (q *Query) SetKey(key string) {
 	q.key = key
 }
 
+/*
+SetKeyProvider sets the provider of API keys, e.g. webapi.KeyPool to rotate several keys.
+If set, it is used instead of the key passed to New or SetKey.
+
+Parameters:
+  - provider: API key provider.
+*/
+func (q *Query) SetKeyProvider(provider webapi.KeyProvider) {
+	q.keys = provider
+}
+
+/*
+SetClient sets the HTTP client used for requests, http.DefaultClient is used by default.
+
+Parameters:
+  - client: HTTP client, for example with a timeout or a custom transport.
+*/
+func (q *Query) SetClient(client *http.Client) {
+	q.client = client
+}
+
+/*
+SetLogger sets the logger for diagnostic messages, nothing is logged by default.
+
+Parameters:
+  - logger: Structured logger.
+*/
+func (q *Query) SetLogger(logger *slog.Logger) {
+	q.logger = logger
+}
+
+/*
+SetObserver sets the observer called after every HTTP request, e.g. to export metrics.
+
+Parameters:
+  - observer: Request observer.
+*/
+func (q *Query) SetObserver(observer webapi.Observer) {
+	q.observer = observer
+}
+
 /*
 SetConcurrency sets the count of concurrent jobs.
 
@@ -90,10 +144,13 @@ SetChunkMax sets the maximum number of file IDs requested for a single chunk.
 Experimentally calculated limit of 220 identifiers per request, after which we get error 414 URI Too Long
 
 Parameters:
-  - count: Count of concurrent jobs.
+  - count: Max count of file IDs in a single request, values less than 1 reset it to the default.
 */
 func (q *Query) SetChunkMax(count int) {
-	q.concurrent = count
+	if count < 1 {
+		count = defaultChunkMax
+	}
+	q.chunkMax = count
 }
 
 /*
@@ -106,6 +163,16 @@ func (q *Query) SetFileIDs(ids []uint64) {
 	q.PublishedFileIDs = ids
 }
 
+/*
+SetAppIDPolicy sets how items with ConsumerAppID not matching AppID are handled.
+
+Parameters:
+  - policy: One of AppIDMismatchFail (default), AppIDMismatchDrop or AppIDMismatchFlag.
+*/
+func (q *Query) SetAppIDPolicy(policy AppIDPolicy) {
+	q.appIDPolicy = policy
+}
+
 /*
 SetAppID sets the Application ID for the GetDetails request.
 
@@ -124,9 +191,13 @@ defined in the Query struct. The method handles authentication using the provide
 sends the request, parses the JSON response, and returns a slice of FileDetail or an error if the
 request fails.
 
+If AppID is set, items with another ConsumerAppID are handled by the policy set with SetAppIDPolicy.
+All chunks are requested and *AppIDMismatchError is returned with mismatched items of all chunks,
+with AppIDMismatchFail (default) details of chunks with mismatched items are not returned.
+
 Returns:
   - A slice of FileDetail containing the details of the requested files.
-  - An error if the request or parsing fails.
+  - An error if the request or parsing fails, or *AppIDMismatchError.
 
 Example:
 
@@ -142,8 +213,8 @@ func (q *Query) Get() ([]FileDetail, error) {
 	if q == nil {
 		return nil, fmt.Errorf("Query request parameters not set")
 	}
-	if len(q.key) != 32 {
-		return nil, fmt.Errorf("Steam API key is empty or does not match")
+	if err := q.checkKey(); err != nil {
+		return nil, err
 	}
 
 	// Split IDs into chunks
@@ -151,37 +222,45 @@ func (q *Query) Get() ([]FileDetail, error) {
 	var allDetails []FileDetail
 
 	// Make requests sequentially
+	var mismatch *AppIDMismatchError
 	for _, c := range chunks {
-		qq := &Query{
-			key:                    q.key,
-			PublishedFileIDs:       c,
-			AppID:                  q.AppID,
-			ShortDescription:       q.ShortDescription,
-			StripDescriptionBBCode: q.StripDescriptionBBCode,
-			IncludeKVTags:          q.IncludeKVTags,
+		details, err := q.chunk(c).getChunk()
+		if err != nil {
+			return allDetails, err
 		}
 
-		details, err := qq.getChunk()
+		details, err = q.checkAppID(details)
 		if err != nil {
-			return allDetails, err
+			mismatch = mismatch.merge(err)
+			if q.appIDPolicy == AppIDMismatchFail {
+				continue
+			}
 		}
 		allDetails = append(allDetails, details...)
 	}
 
+	if mismatch != nil {
+		return allDetails, mismatch
+	}
+
 	return allDetails, nil
 }
 
-// GetConcurrent - same as Get() but requests in parallel with a concurrency limit
+// GetConcurrent - same as Get() but requests in parallel with a concurrency limit.
+// AppID mismatches are handled the same way as in Get(). Failed chunks do not stop other chunks,
+// their errors are logged and returned joined with errors.Join along with details of successful chunks.
 func (q *Query) GetConcurrent() ([]FileDetail, error) {
 	if q == nil {
 		return nil, fmt.Errorf("Query request parameters not set")
 	}
-	if len(q.key) != 32 {
-		return nil, fmt.Errorf("Steam API key is empty or does not match")
+	if err := q.checkKey(); err != nil {
+		return nil, err
 	}
 
 	chunks := splitIntoChunks(q.PublishedFileIDs, q.chunkMax)
 	var allDetails []FileDetail
+	var mismatch *AppIDMismatchError
+	var errs []error
 	var mu sync.Mutex
 	wg := sync.WaitGroup{}
 
@@ -198,39 +277,150 @@ func (q *Query) GetConcurrent() ([]FileDetail, error) {
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			qq := &Query{
-				key:                    q.key,
-				PublishedFileIDs:       c,
-				AppID:                  q.AppID,
-				ShortDescription:       q.ShortDescription,
-				StripDescriptionBBCode: q.StripDescriptionBBCode,
-				IncludeKVTags:          q.IncludeKVTags,
-			}
-
-			details, err := qq.getChunk()
+			details, err := q.chunk(c).getChunk()
 			if err != nil {
-				// For real usage consider passing error via channel to handle them properly
-				fmt.Printf("Error in parallel chunk: %v\n", err)
+				webapi.Logger(q.logger).Error("failed to get file details chunk", "size", len(c), "error", err)
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
 				return
 			}
 
+			details, err = q.checkAppID(details)
+
 			// Merge results with lock
 			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				mismatch = mismatch.merge(err)
+				if q.appIDPolicy == AppIDMismatchFail {
+					return
+				}
+			}
 			allDetails = append(allDetails, details...)
-			mu.Unlock()
 		}()
 	}
 
 	// Wait until all goroutines are done
 	wg.Wait()
 
+	if len(errs) > 0 {
+		if mismatch != nil {
+			errs = append(errs, mismatch)
+		}
+		return allDetails, errors.Join(errs...)
+	}
+	if mismatch != nil {
+		return allDetails, mismatch
+	}
+
 	return allDetails, nil
 }
 
 // getChunk - handles one chunk request
+// with the key provider of several keys it retries with the next key on 403 and 429 responses
 func (q *Query) getChunk() ([]FileDetail, error) {
+	if q.endpoint() == EndpointRemoteStorage {
+		details, _, err := q.requestChunk("", 0)
+		return details, err
+	}
+
+	keys := q.keyProvider()
+	var err error
+	for retries := 0; retries < webapi.Attempts(keys); retries++ {
+		key, kerr := keys.Key()
+		if kerr != nil {
+			if err != nil {
+				return nil, err
+			}
+			return nil, kerr
+		}
+
+		var details []FileDetail
+		var status int
+		details, status, err = q.requestChunk(key, retries)
+		keys.Report(key, status)
+		if !webapi.Retryable(status) {
+			return details, err
+		}
+	}
+
+	return nil, err
+}
+
+// requestChunk - makes one request for the chunk with the key
+func (q *Query) requestChunk(key string, retries int) (details []FileDetail, status int, err error) {
+	info := webapi.RequestInfo{Endpoint: baseURL, ChunkSize: len(q.PublishedFileIDs), Retries: retries}
+	start := time.Now()
+	defer func() {
+		info.Latency = time.Since(start)
+		info.Err = err
+		webapi.Observe(q.observer, info)
+		webapi.Logger(q.logger).Debug("steam api request",
+			"endpoint", info.Endpoint, "size", info.ChunkSize, "status", info.Status,
+			"latency", info.Latency, "bytes", info.Bytes, "retries", info.Retries, "error", err,
+		)
+	}()
+
+	req, err := q.newRequest(key)
+	if err != nil {
+		return nil, 0, webapi.RedactError(err)
+	}
+	info.Endpoint = req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+
+	client := q.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, webapi.RedactError(err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			webapi.Logger(q.logger).Warn("failed to close response body", "error", cerr)
+		}
+	}()
+
+	info.Status = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("received status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	info.Bytes = int64(len(body))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	if q.endpoint() == EndpointRemoteStorage {
+		details, err = decodeRemoteStorage(body)
+	} else {
+		details, err = decodePublishedFile(body)
+	}
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	// Set file details URL if not set
+	for i, f := range details {
+		if f.URL == "" {
+			details[i].URL = fmt.Sprintf("%s%d", baseFileURL, f.PublishedFileID)
+		}
+	}
+
+	return details, resp.StatusCode, nil
+}
+
+// newRequest - builds the HTTP request for the chunk
+func (q *Query) newRequest(key string) (*http.Request, error) {
+	if q.endpoint() == EndpointRemoteStorage {
+		return q.newRemoteStorageRequest()
+	}
+
 	query := url.Values{}
-	query.Set("key", q.key)
+	query.Set("key", key)
 	for i, id := range q.PublishedFileIDs {
 		query.Set("publishedfileids["+strconv.Itoa(i)+"]", strconv.FormatUint(id, 10))
 	}
@@ -265,66 +455,30 @@ func (q *Query) getChunk() ([]FileDetail, error) {
 		}
 	}
 
-	u := baseURL + "?" + query.Encode()
-
-	req, err := http.NewRequest(http.MethodGet, u, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			fmt.Printf("Error close response body: %v\n", cerr)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received status code %d", resp.StatusCode)
-	}
+	return http.NewRequest(http.MethodGet, baseURL+"?"+query.Encode(), nil)
+}
 
+// decodePublishedFile - decodes IPublishedFileService/GetDetails response
+func decodePublishedFile(body []byte) ([]FileDetail, error) {
 	var result struct {
 		Response struct {
 			Details []FileDetail `json:"publishedfiledetails"`
 		} `json:"response"`
 	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
-	// Set file details URL if not set
-	for i, f := range result.Response.Details {
-		if f.URL == "" {
-			result.Response.Details[i].URL = fmt.Sprintf("%s%d", baseFileURL, f.PublishedFileID)
-		}
-	}
-
-	// Return struct if AppID is 0
-	if q.AppID == 0 {
-		return result.Response.Details, nil
-	}
-
-	// Validate AppID == ConsumerAppID
-	for _, f := range result.Response.Details {
-		if q.AppID != f.ConsumerAppID {
-			return result.Response.Details, fmt.Errorf(
-				"not match in response CreatorAppid %d and ConsumerAppid %d for item %d",
-				f.CreatorAppID, f.ConsumerAppID, f.PublishedFileID,
-			)
-		}
-	}
-
 	return result.Response.Details, nil
 }
 
+// chunk - returns copy of query for a chunk of IDs
+func (q *Query) chunk(ids []uint64) *Query {
+	qq := *q
+	qq.PublishedFileIDs = ids
+	return &qq
+}
+
 // splitIntoChunks - helper to split slice into sub-slices
 func splitIntoChunks(ids []uint64, size int) [][]uint64 {
 	if len(ids) == 0 || size <= 0 {
@@ -0,0 +1,241 @@
+package filedetails
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// NameFunc returns the mod folder name (e.g. "@CF") used for a workshop item.
+type NameFunc func(FileDetail) string
+
+// Symlink describes a link from a downloaded workshop item to a mod folder in a server directory.
+type Symlink struct {
+	Source string // Workshop content directory (steamapps/workshop/content/<appid>/<id>)
+	Target string // Mod folder in the server directory (e.g. <server>/@CF)
+}
+
+/*
+SanitizeName converts a workshop title into a folder name safe for the -mod parameter.
+
+ASCII letters, digits, '-' and '.' are kept, any other character (including '_') is replaced with '_'.
+Runs of replaced characters are collapsed into a single '_', leading and trailing '_' and '.' are trimmed.
+
+Parameters:
+  - title: The workshop item title.
+
+Returns:
+  - The sanitized name, may be empty if the title has no allowed characters.
+*/
+func SanitizeName(title string) string {
+	var b strings.Builder
+	b.Grow(len(title))
+
+	underscore := false
+	for _, r := range title {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			b.WriteRune(r)
+			underscore = false
+		default:
+			if !underscore {
+				b.WriteByte('_')
+				underscore = true
+			}
+		}
+	}
+
+	return strings.Trim(b.String(), "_.")
+}
+
+/*
+DefaultName is the default NameFunc, returns "@" followed by the sanitized item title.
+If nothing is left of the title after sanitization, the published file ID is used instead.
+
+Parameters:
+  - fd: The workshop item.
+
+Returns:
+  - Mod folder name like "@Dabs_Framework".
+*/
+func DefaultName(fd FileDetail) string {
+	name := SanitizeName(fd.Title)
+	if name == "" {
+		name = strconv.FormatUint(fd.PublishedFileID, 10)
+	}
+
+	return "@" + name
+}
+
+/*
+ModList builds a semicolon separated list of mod folder names, e.g. "@CF;@Dabs_Framework".
+If name is nil, DefaultName is used. Names colliding with an already used name
+get the published file ID appended, so every item gets its own folder.
+Items without ConsumerAppID (not found, hidden, etc.) are skipped like in SteamCMDScript and SymlinkPlan.
+
+Parameters:
+  - details: Workshop items in the load order.
+  - name: Function returning the folder name for an item.
+
+Returns:
+  - List of mod folders joined by ";".
+*/
+func ModList(details []FileDetail, name NameFunc) string {
+	return strings.Join(modNames(downloadable(details), name), ";")
+}
+
+/*
+ModParam builds the DayZ/Arma server "-mod=" command line parameter, e.g. "-mod=@CF;@Dabs_Framework".
+Items without ConsumerAppID are skipped.
+
+Parameters:
+  - details: Workshop items in the load order.
+  - name: Function returning the folder name for an item, DefaultName if nil.
+
+Returns:
+  - The "-mod=" parameter, or an empty string if there are no items to load.
+*/
+func ModParam(details []FileDetail, name NameFunc) string {
+	list := ModList(details, name)
+	if list == "" {
+		return ""
+	}
+
+	return "-mod=" + list
+}
+
+/*
+SteamCMDArgs builds the steamcmd arguments to download all items, e.g.
+"+login anonymous +workshop_download_item 221100 1559212036 +quit".
+Items without ConsumerAppID (not found, hidden, etc.) are skipped.
+
+Parameters:
+  - details: Workshop items to download.
+  - login: Steam login, "anonymous" if empty.
+
+Returns:
+  - A slice of arguments to pass to steamcmd.
+*/
+func SteamCMDArgs(details []FileDetail, login string) []string {
+	if login == "" {
+		login = "anonymous"
+	}
+
+	args := []string{"+login", login}
+	for _, fd := range downloadable(details) {
+		args = append(args,
+			"+workshop_download_item",
+			strconv.FormatUint(fd.ConsumerAppID, 10),
+			strconv.FormatUint(fd.PublishedFileID, 10),
+		)
+	}
+
+	return append(args, "+quit")
+}
+
+/*
+SteamCMDScript builds a steamcmd script for "steamcmd +runscript <file>" to download all items.
+Items without ConsumerAppID (not found, hidden, etc.) are skipped.
+
+Parameters:
+  - details: Workshop items to download.
+  - login: Steam login, "anonymous" if empty.
+
+Returns:
+  - Script content, one steamcmd command per line.
+*/
+func SteamCMDScript(details []FileDetail, login string) string {
+	if login == "" {
+		login = "anonymous"
+	}
+
+	var b strings.Builder
+	b.WriteString("@ShutdownOnFailedCommand 1\n")
+	b.WriteString("@NoPromptForPassword 1\n")
+	b.WriteString("login " + login + "\n")
+	for _, fd := range downloadable(details) {
+		b.WriteString("workshop_download_item ")
+		b.WriteString(strconv.FormatUint(fd.ConsumerAppID, 10))
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatUint(fd.PublishedFileID, 10))
+		b.WriteByte('\n')
+	}
+	b.WriteString("quit\n")
+
+	return b.String()
+}
+
+/*
+SymlinkPlan builds the list of links from steamcmd download directories
+<steamDir>/steamapps/workshop/content/<appid>/<id> to mod folders <serverDir>/<name>.
+Items without ConsumerAppID are skipped. The plan is not applied, use Symlink.Create for it.
+
+Parameters:
+  - details: Workshop items.
+  - steamDir: The steamcmd install (or force_install_dir) directory containing "steamapps".
+  - serverDir: The game server directory where mod folders are expected.
+  - name: Function returning the folder name for an item, DefaultName if nil.
+
+Returns:
+  - A slice of Symlink in the same order as details.
+*/
+func SymlinkPlan(details []FileDetail, steamDir, serverDir string, name NameFunc) []Symlink {
+	details = downloadable(details)
+	names := modNames(details, name)
+	links := make([]Symlink, 0, len(details))
+
+	for i, fd := range details {
+		links = append(links, Symlink{
+			Source: filepath.Join(
+				steamDir, "steamapps", "workshop", "content",
+				strconv.FormatUint(fd.ConsumerAppID, 10),
+				strconv.FormatUint(fd.PublishedFileID, 10),
+			),
+			Target: filepath.Join(serverDir, names[i]),
+		})
+	}
+
+	return links
+}
+
+// Create creates the symlink, an existing link at Target pointing to Source is left as is.
+func (l Symlink) Create() error {
+	if dst, err := os.Readlink(l.Target); err == nil && dst == l.Source {
+		return nil
+	}
+
+	return os.Symlink(l.Source, l.Target)
+}
+
+// downloadable returns items with ConsumerAppID, others can not be downloaded by steamcmd
+func downloadable(details []FileDetail) []FileDetail {
+	result := make([]FileDetail, 0, len(details))
+	for _, fd := range details {
+		if fd.ConsumerAppID != 0 {
+			result = append(result, fd)
+		}
+	}
+
+	return result
+}
+
+// modNames returns unique folder names for every item
+func modNames(details []FileDetail, name NameFunc) []string {
+	if name == nil {
+		name = DefaultName
+	}
+
+	names := make([]string, len(details))
+	seen := make(map[string]struct{}, len(details))
+	for i, fd := range details {
+		n := name(fd)
+		if _, ok := seen[strings.ToLower(n)]; ok {
+			n += "_" + strconv.FormatUint(fd.PublishedFileID, 10)
+		}
+		seen[strings.ToLower(n)] = struct{}{}
+		names[i] = n
+	}
+
+	return names
+}
@@ -0,0 +1,104 @@
+package filedetails
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testMods() []FileDetail {
+	return []FileDetail{
+		{PublishedFileID: 1559212036, ConsumerAppID: 221100, Title: "CF"},
+		{PublishedFileID: 2545327648, ConsumerAppID: 221100, Title: "Dabs Framework"},
+		{PublishedFileID: 1, ConsumerAppID: 221100, Title: " [DayZ] Mod: v1.2! "},
+		{PublishedFileID: 2, ConsumerAppID: 221100, Title: "cf"},
+		{PublishedFileID: 3, ConsumerAppID: 221100, Title: "Моды"},
+		{PublishedFileID: 4, Title: "Missing"},
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	cases := map[string]string{
+		"CF":                  "CF",
+		"Dabs Framework":      "Dabs_Framework",
+		" [DayZ] Mod: v1.2! ": "DayZ_Mod_v1.2",
+		"a__b":                "a_b",
+		"...":                 "",
+	}
+
+	for in, want := range cases {
+		if got := SanitizeName(in); got != want {
+			t.Errorf("SanitizeName(%q) = %q, expected %q", in, got, want)
+		}
+	}
+}
+
+func TestModParam(t *testing.T) {
+	want := "-mod=@CF;@Dabs_Framework;@DayZ_Mod_v1.2;@cf_2;@3"
+	if got := ModParam(testMods(), nil); got != want {
+		t.Errorf("ModParam() = %q, expected %q", got, want)
+	}
+
+	missing := testMods()[5:]
+	if got := ModParam(missing, nil); got != "" {
+		t.Errorf("ModParam() of items without app ID = %q, expected empty", got)
+	}
+
+	upper := func(fd FileDetail) string { return "@" + strings.ToUpper(fd.Title) }
+	if got := ModParam(testMods()[:2], upper); got != "-mod=@CF;@DABS FRAMEWORK" {
+		t.Errorf("ModParam() with custom name = %q", got)
+	}
+
+	if got := ModParam(nil, nil); got != "" {
+		t.Errorf("ModParam(nil) = %q, expected empty", got)
+	}
+}
+
+func TestSteamCMD(t *testing.T) {
+	args := SteamCMDArgs(testMods()[:2], "")
+	want := "+login anonymous +workshop_download_item 221100 1559212036 +workshop_download_item 221100 2545327648 +quit"
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("SteamCMDArgs() = %q, expected %q", got, want)
+	}
+
+	script := SteamCMDScript(testMods(), "user")
+	if !strings.Contains(script, "login user\n") {
+		t.Errorf("SteamCMDScript() has no login: %q", script)
+	}
+	if strings.Count(script, "workshop_download_item") != 5 {
+		t.Errorf("SteamCMDScript() must skip items without app ID: %q", script)
+	}
+}
+
+func TestSymlinkPlan(t *testing.T) {
+	links := SymlinkPlan(testMods(), "/steam", "/server", nil)
+	if len(links) != 5 {
+		t.Fatalf("SymlinkPlan() returned %d links, expected 5", len(links))
+	}
+
+	want := Symlink{
+		Source: filepath.Join("/steam", "steamapps", "workshop", "content", "221100", "2545327648"),
+		Target: filepath.Join("/server", "@Dabs_Framework"),
+	}
+	if links[1] != want {
+		t.Errorf("SymlinkPlan()[1] = %+v, expected %+v", links[1], want)
+	}
+
+	folders := strings.Split(ModList(testMods(), nil), ";")
+	for i, l := range links {
+		if filepath.Base(l.Target) != folders[i] {
+			t.Errorf("SymlinkPlan()[%d] links %s, ModList() has %s", i, l.Target, folders[i])
+		}
+	}
+}
+
+func TestSymlinkCreate(t *testing.T) {
+	dir := t.TempDir()
+	link := Symlink{Source: dir, Target: filepath.Join(dir, "@CF")}
+
+	for i := 0; i < 2; i++ {
+		if err := link.Create(); err != nil {
+			t.Fatalf("Create() #%d: %v", i, err)
+		}
+	}
+}
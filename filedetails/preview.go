@@ -0,0 +1,336 @@
+package filedetails
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// PreviewTypeImage is the Previews.PreviewType of an image preview.
+	PreviewTypeImage = 0
+	// PreviewTypeYouTube is the Previews.PreviewType of a YouTube video preview, it has no downloadable media.
+	PreviewTypeYouTube = 1
+
+	defaultPreviewMaxSize int64 = 10 << 20
+)
+
+// previewType is an allowed preview content type with the file extension
+type previewType struct {
+	contentType string
+	ext         string
+}
+
+// previewTypes are default preview content types with extensions in the order of lookup
+var previewTypes = []previewType{
+	{"image/jpeg", ".jpg"},
+	{"image/png", ".png"},
+	{"image/gif", ".gif"},
+	{"image/webp", ".webp"},
+}
+
+// Manifest maps PublishedFileID to the local paths of the downloaded previews.
+// The main preview (FileDetail.PreviewURL) always goes first if it was downloaded.
+type Manifest map[uint64][]string
+
+// Downloader saves workshop preview images of FileDetail to a local directory.
+type Downloader struct {
+	client       *http.Client  // HTTP client used for downloads
+	contentTypes []previewType // Allowed content types with file extensions in the order of lookup
+	dir          string        // Destination directory
+	maxSize      int64         // Maximum size of a single preview in bytes
+	concurrent   int           // Concurrent downloads
+}
+
+// preview is a single media file to download
+type preview struct {
+	url  string
+	base string
+	id   uint64
+	size int64
+}
+
+/*
+NewDownloader creates a new Downloader saving previews to the dir directory.
+Every item gets its own subdirectory named by PublishedFileID, the main preview
+is saved as "preview.<ext>" and additional previews as "<PreviewID>.<ext>".
+
+By default it uses http.DefaultClient, allows JPEG, PNG, GIF and WebP images up to 10 MiB
+and runs 10 downloads concurrently.
+
+Parameters:
+  - dir: Destination directory, created if not exists.
+
+Returns:
+  - A pointer to a Downloader instance.
+*/
+func NewDownloader(dir string) *Downloader {
+	return &Downloader{
+		client:       http.DefaultClient,
+		contentTypes: append([]previewType(nil), previewTypes...),
+		dir:          dir,
+		maxSize:      defaultPreviewMaxSize,
+		concurrent:   defaultConns,
+	}
+}
+
+/*
+SetClient sets the HTTP client used for downloads.
+
+Parameters:
+  - client: HTTP client, http.DefaultClient is used if nil.
+*/
+func (d *Downloader) SetClient(client *http.Client) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	d.client = client
+}
+
+/*
+SetConcurrency sets the count of concurrent downloads.
+
+Parameters:
+  - count: Count of concurrent downloads.
+*/
+func (d *Downloader) SetConcurrency(count int) {
+	d.concurrent = count
+}
+
+/*
+SetMaxSize sets the maximum size of a single preview, larger previews are skipped with an error.
+
+Parameters:
+  - size: Size limit in bytes, 0 disables the limit.
+*/
+func (d *Downloader) SetMaxSize(size int64) {
+	d.maxSize = size
+}
+
+/*
+SetContentTypes sets the allowed content types of previews, existing files are looked up by
+extensions in the order of types. Only "image/*" types are accepted, other types are ignored.
+Types unknown to the package get the first extension from the mime database,
+or the subtype as the extension (e.g. ".avif" for "image/avif") if the database has none.
+
+Parameters:
+  - types: Allowed content types (e.g. "image/png").
+*/
+func (d *Downloader) SetContentTypes(types ...string) {
+	d.contentTypes = make([]previewType, 0, len(types))
+	for _, t := range types {
+		if ext := previewExtension(t); ext != "" {
+			d.contentTypes = append(d.contentTypes, previewType{contentType: t, ext: ext})
+		}
+	}
+}
+
+// previewExtension returns the file extension of the image content type, or empty string if not an image
+func previewExtension(contentType string) string {
+	for _, pt := range previewTypes {
+		if pt.contentType == contentType {
+			return pt.ext
+		}
+	}
+
+	subtype, ok := strings.CutPrefix(contentType, "image/")
+	if !ok || subtype == "" {
+		return ""
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	for _, r := range subtype {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+
+	return "." + subtype
+}
+
+// extension returns the file extension of the allowed content type
+func (d *Downloader) extension(contentType string) (string, bool) {
+	for _, pt := range d.contentTypes {
+		if pt.contentType == contentType {
+			return pt.ext, true
+		}
+	}
+
+	return "", false
+}
+
+/*
+Download saves preview images of the file details to the destination directory.
+
+The main preview (PreviewURL) and additional image previews (Previews with PreviewTypeImage)
+are downloaded concurrently. Previews already saved in the directory are not downloaded again.
+Video previews are skipped since they have no media to download.
+
+Parameters:
+  - details: Workshop items with previews, use Query.IncludeAdditionalPreviews to get additional ones.
+
+Returns:
+  - Manifest of local files for every item with at least one saved preview.
+  - An error joining all failed downloads, the manifest is filled anyway.
+*/
+func (d *Downloader) Download(details []FileDetail) (Manifest, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		errs     []error
+		manifest = make(Manifest)
+		saved    = make(map[string]string)
+	)
+
+	previews := d.previews(details)
+	sem := make(chan struct{}, max(d.concurrent, 1))
+
+	for _, p := range previews {
+		p := p // local copy for goroutine
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Acquire slot
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			path, err := d.download(p)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("preview %s of item %d: %w", p.url, p.id, err))
+				return
+			}
+			saved[p.base] = path
+		}()
+	}
+
+	wg.Wait()
+
+	// Build manifest in the preview order
+	for _, p := range previews {
+		if path, ok := saved[p.base]; ok {
+			manifest[p.id] = append(manifest[p.id], path)
+		}
+	}
+
+	return manifest, errors.Join(errs...)
+}
+
+// previews collects downloadable previews of all items
+func (d *Downloader) previews(details []FileDetail) []preview {
+	var list []preview
+	for _, fd := range details {
+		dir := filepath.Join(d.dir, strconv.FormatUint(fd.PublishedFileID, 10))
+
+		if fd.PreviewURL != "" {
+			list = append(list, preview{
+				id:   fd.PublishedFileID,
+				url:  fd.PreviewURL,
+				base: filepath.Join(dir, "preview"),
+				size: int64(fd.PreviewFileSize), // #nosec G115
+			})
+		}
+
+		for i, p := range fd.Previews {
+			if p.PreviewType != PreviewTypeImage || p.URL == "" {
+				continue
+			}
+			name := strconv.FormatUint(p.PreviewID, 10)
+			if p.PreviewID == 0 {
+				name = strconv.Itoa(i)
+			}
+			list = append(list, preview{
+				id:   fd.PublishedFileID,
+				url:  p.URL,
+				base: filepath.Join(dir, name),
+				size: int64(p.Size),
+			})
+		}
+	}
+
+	return list
+}
+
+// download saves one preview and returns path to the file
+func (d *Downloader) download(p preview) (string, error) {
+	// Skip if already downloaded with any allowed extension
+	for _, pt := range d.contentTypes {
+		if info, err := os.Stat(p.base + pt.ext); err == nil && info.Size() > 0 {
+			return p.base + pt.ext, nil
+		}
+	}
+
+	if d.maxSize > 0 && p.size > d.maxSize {
+		return "", fmt.Errorf("size %d exceeds limit %d", p.size, d.maxSize)
+	}
+
+	resp, err := d.client.Get(p.url)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("received status code %d", resp.StatusCode)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return "", fmt.Errorf("bad content type: %w", err)
+	}
+	ext, ok := d.extension(mediaType)
+	if !ok {
+		return "", fmt.Errorf("content type %s not allowed", mediaType)
+	}
+
+	if d.maxSize > 0 && resp.ContentLength > d.maxSize {
+		return "", fmt.Errorf("size %d exceeds limit %d", resp.ContentLength, d.maxSize)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.base), 0o755); err != nil { // #nosec G301
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p.base), ".preview-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	var body io.Reader = resp.Body
+	if d.maxSize > 0 {
+		body = io.LimitReader(resp.Body, d.maxSize+1)
+	}
+
+	n, err := io.Copy(tmp, body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	if d.maxSize > 0 && n > d.maxSize {
+		return "", fmt.Errorf("size exceeds limit %d", d.maxSize)
+	}
+
+	path := p.base + ext
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil { // #nosec G302
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -0,0 +1,110 @@
+package filedetails
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDownloadPreviews(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("png"))
+		case "/jpeg":
+			w.Header().Set("Content-Type", "image/jpeg; charset=binary")
+			_, _ = w.Write([]byte("jpeg"))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html>"))
+		case "/big":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	details := []FileDetail{
+		{
+			PublishedFileID: 1,
+			PreviewURL:      srv.URL + "/png",
+			Previews: []Previews{
+				{PreviewID: 10, URL: srv.URL + "/jpeg", PreviewType: PreviewTypeImage},
+				{PreviewID: 11, YoutubeVideoID: "abc", PreviewType: PreviewTypeYouTube},
+				{PreviewID: 12, URL: srv.URL + "/huge", PreviewType: PreviewTypeImage, Size: 1 << 20},
+			},
+		},
+		{PublishedFileID: 2, PreviewURL: srv.URL + "/html"},
+		{PublishedFileID: 3, PreviewURL: srv.URL + "/big"},
+		{PublishedFileID: 4, PreviewURL: srv.URL + "/missing"},
+	}
+
+	dir := t.TempDir()
+	d := NewDownloader(dir)
+	d.SetClient(srv.Client())
+	d.SetMaxSize(32)
+
+	manifest, err := d.Download(details)
+	if err == nil {
+		t.Error("Download() must return errors for bad previews")
+	}
+
+	want := []string{filepath.Join(dir, "1", "preview.png"), filepath.Join(dir, "1", "10.jpg")}
+	if got := manifest[1]; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("manifest[1] = %v, expected %v", got, want)
+	}
+	for _, id := range []uint64{2, 3, 4} {
+		if _, ok := manifest[id]; ok {
+			t.Errorf("manifest must not contain item %d", id)
+		}
+	}
+	if n := hits.Load(); n != 5 {
+		t.Errorf("server got %d requests, expected 5", n)
+	}
+
+	// Second run must skip existing files
+	hits.Store(0)
+	manifest, _ = d.Download(details[:1])
+	if len(manifest[1]) != 2 {
+		t.Errorf("manifest[1] = %v, expected 2 files", manifest[1])
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("server got %d requests for existing files, expected 0", n)
+	}
+}
+
+func TestPreviewContentTypes(t *testing.T) {
+	d := NewDownloader(t.TempDir())
+	d.SetContentTypes("image/png", "text/html", "image/zzz", "image/x-bad+type", "image/jpeg")
+
+	want := []previewType{{"image/png", ".png"}, {"image/zzz", ".zzz"}, {"image/jpeg", ".jpg"}}
+	if !reflect.DeepEqual(d.contentTypes, want) {
+		t.Errorf("contentTypes = %v, expected %v", d.contentTypes, want)
+	}
+
+	// existing files are looked up in the order of content types
+	base := filepath.Join(d.dir, "1", "preview")
+	if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{".jpg", ".png"} {
+		if err := os.WriteFile(base+ext, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if path, err := d.download(preview{base: base}); err != nil || path != base+".png" {
+			t.Fatalf("download() = %s, %v, expected existing %s", path, err, base+".png")
+		}
+	}
+}
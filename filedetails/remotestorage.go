@@ -0,0 +1,192 @@
+package filedetails
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/woozymasta/steam/utils/webapi"
+)
+
+// Endpoint selects the Steam API endpoint used by Query.
+type Endpoint int
+
+const (
+	// EndpointAuto uses EndpointPublishedFile if the API key is set, EndpointRemoteStorage otherwise (default).
+	EndpointAuto Endpoint = iota
+
+	// EndpointPublishedFile uses IPublishedFileService/GetDetails, requires an API key.
+	EndpointPublishedFile
+
+	/*
+		EndpointRemoteStorage uses the keyless ISteamRemoteStorage/GetPublishedFileDetails POST endpoint.
+
+		It returns only part of the fields, the following FileDetail fields are always empty:
+		AppName, BanTextCheckResult, Banner, CanBeDeleted, CanSubscribe, Children, ConsumerShortcutID,
+		FileType, Flags, Followers, ImageHeight, ImageWidth, KVTags, Language, LifetimeFollowers,
+		LifetimePlaytime, LifetimePlaytimeSessions, MaybeInappropriateSex, MaybeInappropriateViolence,
+		NumChildren, NumCommentsPublic, NumReports, PreviewFileSize, Previews, Reactions, Revision,
+		RevisionChangeNumber, ShowSubscribeAll, VoteData, WorkshopAccepted, WorkshopFile, YoutubeVideoID.
+		Tags have only the Tag field, DisplayName is set to the same value.
+		Query options (Language, Include*, ShortDescription, etc.) are ignored.
+	*/
+	EndpointRemoteStorage
+)
+
+const remoteStorageURL string = "https://api.steampowered.com/ISteamRemoteStorage/GetPublishedFileDetails/v1/"
+
+/*
+SetEndpoint sets the Steam API endpoint used for requests.
+
+Parameters:
+  - endpoint: One of EndpointAuto (default), EndpointPublishedFile or EndpointRemoteStorage.
+*/
+func (q *Query) SetEndpoint(endpoint Endpoint) {
+	q.endpointMode = endpoint
+}
+
+// endpoint - resolves EndpointAuto to the actual endpoint
+func (q *Query) endpoint() Endpoint {
+	if q.endpointMode == EndpointAuto {
+		if q.key == "" && q.keys == nil {
+			return EndpointRemoteStorage
+		}
+		return EndpointPublishedFile
+	}
+
+	return q.endpointMode
+}
+
+// keyProvider - returns the key provider or the static key
+func (q *Query) keyProvider() webapi.KeyProvider {
+	if q.keys != nil {
+		return q.keys
+	}
+
+	return webapi.StaticKey(q.key)
+}
+
+// checkKey - validates the API key for the selected endpoint
+func (q *Query) checkKey() error {
+	if q.endpoint() == EndpointPublishedFile && q.keys == nil && len(q.key) != 32 {
+		return fmt.Errorf("Steam API key is empty or does not match")
+	}
+
+	return nil
+}
+
+// newRemoteStorageRequest - builds POST request to ISteamRemoteStorage/GetPublishedFileDetails
+func (q *Query) newRemoteStorageRequest() (*http.Request, error) {
+	form := url.Values{}
+	form.Set("itemcount", strconv.Itoa(len(q.PublishedFileIDs)))
+	for i, id := range q.PublishedFileIDs {
+		form.Set("publishedfileids["+strconv.Itoa(i)+"]", strconv.FormatUint(id, 10))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, remoteStorageURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req, nil
+}
+
+// remoteStorageDetail - item of ISteamRemoteStorage/GetPublishedFileDetails response
+type remoteStorageDetail struct {
+	BanReason             string                 `json:"ban_reason"`
+	Description           string                 `json:"description"`
+	FileURL               string                 `json:"file_url"`
+	Filename              string                 `json:"filename"`
+	PreviewURL            string                 `json:"preview_url"`
+	Title                 string                 `json:"title"`
+	Tags                  []struct{ Tag string } `json:"tags"`
+	ConsumerAppID         flexUint               `json:"consumer_app_id"`
+	Creator               flexUint               `json:"creator"`
+	CreatorAppID          flexUint               `json:"creator_app_id"`
+	FileSize              flexUint               `json:"file_size"`
+	HContentFile          flexUint               `json:"hcontent_file"`
+	HContentPreview       flexUint               `json:"hcontent_preview"`
+	PublishedFileID       flexUint               `json:"publishedfileid"`
+	TimeCreated           int64                  `json:"time_created"`
+	TimeUpdated           int64                  `json:"time_updated"`
+	Banned                int                    `json:"banned"`
+	Favorited             int                    `json:"favorited"`
+	LifetimeFavorited     int                    `json:"lifetime_favorited"`
+	LifetimeSubscriptions int                    `json:"lifetime_subscriptions"`
+	Result                int                    `json:"result"`
+	Subscriptions         int                    `json:"subscriptions"`
+	Views                 int                    `json:"views"`
+	Visibility            int                    `json:"visibility"`
+}
+
+// decodeRemoteStorage - decodes ISteamRemoteStorage/GetPublishedFileDetails response into FileDetail
+func decodeRemoteStorage(body []byte) ([]FileDetail, error) {
+	var result struct {
+		Response struct {
+			Details []remoteStorageDetail `json:"publishedfiledetails"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	details := make([]FileDetail, len(result.Response.Details))
+	for i, d := range result.Response.Details {
+		fd := FileDetail{
+			PublishedFileID:       uint64(d.PublishedFileID),
+			Result:                d.Result,
+			Creator:               uint64(d.Creator),
+			CreatorAppID:          uint64(d.CreatorAppID),
+			ConsumerAppID:         uint64(d.ConsumerAppID),
+			Filename:              d.Filename,
+			FileSize:              uint64(d.FileSize),
+			HContentFile:          uint64(d.HContentFile),
+			PreviewURL:            d.PreviewURL,
+			HContentPreview:       uint64(d.HContentPreview),
+			Title:                 d.Title,
+			FileDescription:       d.Description,
+			TimeCreated:           time.Unix(d.TimeCreated, 0),
+			TimeUpdated:           time.Unix(d.TimeUpdated, 0),
+			Visibility:            d.Visibility,
+			Banned:                d.Banned != 0,
+			BanReason:             d.BanReason,
+			Subscriptions:         d.Subscriptions,
+			Favorited:             d.Favorited,
+			LifetimeSubscriptions: d.LifetimeSubscriptions,
+			LifetimeFavorited:     d.LifetimeFavorited,
+			Views:                 d.Views,
+			RemoteStorage:         true,
+		}
+		for _, t := range d.Tags {
+			fd.Tags = append(fd.Tags, Tags{Tag: t.Tag, DisplayName: t.Tag})
+		}
+		details[i] = fd
+	}
+
+	return details, nil
+}
+
+// flexUint - uint64 encoded in JSON either as number or as string
+type flexUint uint64
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the flexUint type.
+func (f *flexUint) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	*f = flexUint(v)
+	return nil
+}
@@ -0,0 +1,89 @@
+package filedetails_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/woozymasta/steam/filedetails"
+	"github.com/woozymasta/steam/steamtest"
+)
+
+func TestGetKeyless(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+
+	updated := time.Unix(1700000000, 0)
+	srv.AddFiles(filedetails.FileDetail{
+		PublishedFileID: 1559212036,
+		ConsumerAppID:   221100,
+		CreatorAppID:    221100,
+		Creator:         76561198000000000,
+		FileSize:        1 << 40,
+		Title:           "CF",
+		FileDescription: "Community Framework",
+		TimeUpdated:     updated,
+		Banned:          true,
+		Tags:            []filedetails.Tags{{Tag: "Mod", DisplayName: "Mod"}},
+	})
+
+	query := filedetails.New([]uint64{1559212036, 42}, "")
+	if query == nil {
+		t.Fatal("New() must allow empty key")
+	}
+	query.SetClient(srv.Client())
+	query.SetAppID(221100)
+
+	files, err := query.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Get() returned %d files, expected 2", len(files))
+	}
+
+	cf := files[0]
+	if cf.Title != "CF" || cf.FileDescription != "Community Framework" || cf.FileSize != 1<<40 ||
+		cf.Creator != 76561198000000000 || !cf.Banned || !cf.TimeUpdated.Equal(updated) || cf.Result != 1 {
+		t.Errorf("unexpected file %+v", cf)
+	}
+	if len(cf.Tags) != 1 || cf.Tags[0].Tag != "Mod" {
+		t.Errorf("unexpected tags %+v", cf.Tags)
+	}
+	if cf.URL == "" {
+		t.Error("URL must be set")
+	}
+	if !cf.RemoteStorage {
+		t.Error("RemoteStorage must be set")
+	}
+	for _, f := range filedetails.Audit(files[:1]) {
+		if f.Code == filedetails.FindingNotSubscribable {
+			t.Errorf("keyless item is reported as %+v", f)
+		}
+	}
+	if files[1].PublishedFileID != 42 || files[1].Result != 9 {
+		t.Errorf("unexpected missing file %+v", files[1])
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 || reqs[0].Method != http.MethodPost || reqs[0].Path != steamtest.PathGetPublishedFileDetails ||
+		reqs[0].Form.Get("itemcount") != "2" || reqs[0].Query.Has("key") {
+		t.Errorf("unexpected request %+v", reqs)
+	}
+
+	// Explicit endpoint selection
+	query.SetKey(steamtest.Key)
+	query.SetEndpoint(filedetails.EndpointRemoteStorage)
+	if _, err := query.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if reqs = srv.Requests(); reqs[1].Path != steamtest.PathGetPublishedFileDetails {
+		t.Errorf("request sent to %s", reqs[1].Path)
+	}
+
+	query.SetKey("")
+	query.SetEndpoint(filedetails.EndpointPublishedFile)
+	if _, err := query.Get(); err == nil {
+		t.Error("Get() must fail without key for EndpointPublishedFile")
+	}
+}
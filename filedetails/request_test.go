@@ -0,0 +1,31 @@
+package filedetails
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRequestRedaction(t *testing.T) {
+	key := "0123456789ABCDEF0123456789ABCDEF"
+
+	// Invalid base URL fails before the request is sent
+	defer func(u string) { baseURL = u }(baseURL)
+	baseURL = "://invalid"
+
+	_, err := New([]uint64{1}, key).Get()
+	if err == nil {
+		t.Fatal("request must fail")
+	}
+	if strings.Contains(err.Error(), key) {
+		t.Errorf("error contains API key: %v", err)
+	}
+	if !strings.Contains(err.Error(), "key=") {
+		t.Errorf("error lost details: %v", err)
+	}
+	var uerr *url.Error
+	if !errors.As(err, &uerr) || strings.Contains(uerr.URL, key) {
+		t.Errorf("url.Error is not redacted: %v", err)
+	}
+}
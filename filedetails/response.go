@@ -64,6 +64,8 @@ type FileDetail struct {
 	ShowSubscribeAll           bool        `json:"show_subscribe_all,omitempty"`                // Indicates if "subscribe to all" is available for the file.
 	WorkshopAccepted           bool        `json:"workshop_accepted,omitempty"`                 // Indicates if the file was accepted in the workshop.
 	WorkshopFile               bool        `json:"workshop_file"`                               // Indicates if the file is a workshop file.
+	AppIDMismatch              bool        `json:"-"`                                           // Set by AppIDMismatchFlag policy if ConsumerAppID not match Query.AppID.
+	RemoteStorage              bool        `json:"-"`                                           // Set if requested with EndpointRemoteStorage, fields not returned by it are empty.
 }
 
 // Custom implementation for deserializing UnixTime in `FileDetail`
@@ -0,0 +1,143 @@
+package filedetails
+
+import (
+	"sort"
+	"time"
+)
+
+// ScoreBuckets is the count of vote score buckets in Stats, each covers 0.1 of the score.
+const ScoreBuckets = 10
+
+// ItemStat is a summary of a single workshop item in Stats.
+type ItemStat struct {
+	TimeUpdated     time.Time     // The timestamp when the file was last updated, zero if unknown.
+	Title           string        // The title of the file.
+	Age             time.Duration // Time since the last update, 0 if unknown.
+	PublishedFileID uint64        // The unique ID of the published file.
+	Size            uint64        // The download size of the file in bytes.
+	Score           float64       // The vote score of the file, 0 if not rated.
+	Rated           bool          // Indicates if the file has votes.
+}
+
+// Stats is an aggregate summary of a set of workshop items, e.g. a server modpack.
+type Stats struct {
+	Tags          map[string]int    // Count of items by tag.
+	Items         []ItemStat        // Items sorted by size, largest first.
+	Scores        [ScoreBuckets]int // Vote score distribution, Scores[i] counts items with score in [i/10, (i+1)/10).
+	TotalSize     uint64            // Total download size in bytes.
+	Count         int               // Count of items.
+	Subscriptions int               // Total current subscriptions.
+	Favorites     int               // Total current favorites.
+	Unrated       int               // Count of items without votes.
+}
+
+/*
+CollectStats summarizes the workshop items: total and per-item download size, subscriptions,
+favorites, vote score distribution, time since the last update and counts by tag.
+Use Query.IncludeTags and Query.IncludeVotes to get tags and votes in the details.
+
+Parameters:
+  - details: Workshop items.
+
+Returns:
+  - Stats of the items, ages are calculated from the current time.
+*/
+func CollectStats(details []FileDetail) Stats {
+	return collectStats(details, time.Now())
+}
+
+// collectStats - CollectStats with the given current time
+func collectStats(details []FileDetail, now time.Time) Stats {
+	stats := Stats{
+		Tags:  make(map[string]int),
+		Items: make([]ItemStat, 0, len(details)),
+		Count: len(details),
+	}
+
+	for _, fd := range details {
+		item := ItemStat{
+			PublishedFileID: fd.PublishedFileID,
+			Title:           fd.Title,
+			Size:            fd.FileSize,
+		}
+		if known(fd.TimeUpdated) {
+			item.TimeUpdated = fd.TimeUpdated
+			item.Age = now.Sub(fd.TimeUpdated)
+		}
+
+		if len(fd.VoteData) > 0 && fd.VoteData[0].VotesUp+fd.VoteData[0].VotesDown > 0 {
+			item.Rated = true
+			item.Score = fd.VoteData[0].Score
+
+			bucket := int(item.Score * ScoreBuckets)
+			bucket = min(max(bucket, 0), ScoreBuckets-1)
+			stats.Scores[bucket]++
+		} else {
+			stats.Unrated++
+		}
+
+		seen := make(map[string]struct{}, len(fd.Tags))
+		for _, t := range fd.Tags {
+			if _, ok := seen[t.Tag]; ok {
+				continue
+			}
+			seen[t.Tag] = struct{}{}
+			stats.Tags[t.Tag]++
+		}
+
+		stats.TotalSize += fd.FileSize
+		stats.Subscriptions += fd.Subscriptions
+		stats.Favorites += fd.Favorited
+		stats.Items = append(stats.Items, item)
+	}
+
+	sort.SliceStable(stats.Items, func(i, j int) bool {
+		return stats.Items[i].Size > stats.Items[j].Size
+	})
+
+	return stats
+}
+
+// Largest returns up to n largest items.
+func (s *Stats) Largest(n int) []ItemStat {
+	return s.Items[:min(max(n, 0), len(s.Items))]
+}
+
+// Stale returns items not updated for at least age, oldest first.
+// Items without update time are not included.
+func (s *Stats) Stale(age time.Duration) []ItemStat {
+	var stale []ItemStat
+	for _, item := range s.Items {
+		if !item.TimeUpdated.IsZero() && item.Age >= age {
+			stale = append(stale, item)
+		}
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].Age > stale[j].Age
+	})
+
+	return stale
+}
+
+// known - reports whether the time is set, missing timestamps are decoded as the Unix epoch
+func known(t time.Time) bool {
+	return t.Unix() > 0
+}
+
+// AverageScore returns the average vote score of rated items, 0 if no item is rated.
+func (s *Stats) AverageScore() float64 {
+	var sum float64
+	var count int
+	for _, item := range s.Items {
+		if item.Rated {
+			sum += item.Score
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+
+	return sum / float64(count)
+}
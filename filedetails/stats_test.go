@@ -0,0 +1,87 @@
+package filedetails
+
+import (
+	"testing"
+	"time"
+
+	json "github.com/json-iterator/go"
+)
+
+func TestCollectStats(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	details := []FileDetail{
+		{
+			PublishedFileID: 1, Title: "CF", FileSize: 100, Subscriptions: 10, Favorited: 1,
+			TimeUpdated: now.Add(-24 * time.Hour),
+			VoteData:    []VoteData{{Score: 0.95, VotesUp: 100, VotesDown: 5}},
+			Tags:        []Tags{{Tag: "Mod"}, {Tag: "Scripts"}, {Tag: "Mod"}},
+		},
+		{
+			PublishedFileID: 2, Title: "Map", FileSize: 5000, Subscriptions: 5, Favorited: 2,
+			TimeUpdated: now.Add(-400 * 24 * time.Hour),
+			VoteData:    []VoteData{{Score: 0.41, VotesUp: 4, VotesDown: 6}},
+			Tags:        []Tags{{Tag: "Mod"}, {Tag: "Map"}},
+		},
+		{
+			PublishedFileID: 3, Title: "New", FileSize: 300,
+			TimeUpdated: now.Add(-200 * 24 * time.Hour),
+			VoteData:    []VoteData{{Score: 0}},
+		},
+		{PublishedFileID: 4, Title: "Missing"},
+	}
+
+	s := collectStats(details, now)
+
+	if s.Count != 4 || s.TotalSize != 5400 || s.Subscriptions != 15 || s.Favorites != 3 {
+		t.Errorf("unexpected totals %+v", s)
+	}
+	if s.Tags["Mod"] != 2 || s.Tags["Scripts"] != 1 || s.Tags["Map"] != 1 {
+		t.Errorf("unexpected tags %v", s.Tags)
+	}
+	if s.Scores[9] != 1 || s.Scores[4] != 1 || s.Unrated != 2 {
+		t.Errorf("unexpected scores %v, unrated %d", s.Scores, s.Unrated)
+	}
+	if avg := s.AverageScore(); avg < 0.67 || avg > 0.69 {
+		t.Errorf("AverageScore() = %f", avg)
+	}
+
+	largest := s.Largest(2)
+	if len(largest) != 2 || largest[0].PublishedFileID != 2 || largest[1].PublishedFileID != 3 {
+		t.Errorf("Largest(2) = %+v", largest)
+	}
+	if len(s.Largest(10)) != 4 {
+		t.Error("Largest(10) must return all items")
+	}
+
+	stale := s.Stale(180 * 24 * time.Hour)
+	if len(stale) != 2 || stale[0].PublishedFileID != 2 || stale[1].PublishedFileID != 3 {
+		t.Errorf("Stale() = %+v", stale)
+	}
+}
+
+func TestCollectStatsUnknownTime(t *testing.T) {
+	var details []FileDetail
+	for _, data := range []string{
+		`{"publishedfileid":"1","result":9}`,
+		`{"publishedfileid":"2","result":1,"time_updated":0}`,
+		`{"publishedfileid":"3","result":1,"time_updated":1700000000}`,
+	} {
+		var fd FileDetail
+		if err := json.Unmarshal([]byte(data), &fd); err != nil {
+			t.Fatal(err)
+		}
+		details = append(details, fd)
+	}
+
+	s := collectStats(details, time.Unix(1800000000, 0))
+	for _, item := range s.Items {
+		if known := item.PublishedFileID == 3; known != !item.TimeUpdated.IsZero() || known != (item.Age > 0) {
+			t.Errorf("item %d: update time %v, age %s", item.PublishedFileID, item.TimeUpdated, item.Age)
+		}
+	}
+
+	stale := s.Stale(24 * time.Hour)
+	if len(stale) != 1 || stale[0].PublishedFileID != 3 {
+		t.Errorf("Stale() = %+v", stale)
+	}
+}
@@ -0,0 +1,159 @@
+package filedetails_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/woozymasta/steam/filedetails"
+	"github.com/woozymasta/steam/steamtest"
+	"github.com/woozymasta/steam/utils/webapi"
+)
+
+// recorder collects observed requests
+type recorder struct {
+	infos []webapi.RequestInfo
+	mu    sync.Mutex
+}
+
+func (r *recorder) ObserveRequest(info webapi.RequestInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.infos = append(r.infos, info)
+}
+
+// failTransport fails every request
+type failTransport struct{}
+
+func (failTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestObserver(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+	srv.AddFiles(filedetails.FileDetail{PublishedFileID: 1, Title: "A"})
+	srv.FailNext(http.StatusInternalServerError, 1)
+
+	rec := &recorder{}
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	query := filedetails.New([]uint64{1, 2, 3}, steamtest.Key)
+	query.SetClient(srv.Client())
+	query.SetObserver(rec)
+	query.SetLogger(logger)
+	query.SetChunkMax(2)
+
+	files, err := query.GetConcurrent()
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("GetConcurrent() error %v, expected error of the failed chunk", err)
+	}
+	if len(files) == 0 {
+		t.Error("GetConcurrent() must return details of successful chunks")
+	}
+
+	if len(rec.infos) != 2 {
+		t.Fatalf("observer called %d times, expected 2", len(rec.infos))
+	}
+
+	failed, chunks := 0, 0
+	for _, info := range rec.infos {
+		if info.Status == http.StatusInternalServerError {
+			failed++
+			if info.Err == nil {
+				t.Error("failed request must have error")
+			}
+		}
+		chunks += info.ChunkSize
+		if info.Latency <= 0 {
+			t.Error("latency is not measured")
+		}
+		if !strings.HasSuffix(info.Endpoint, "/IPublishedFileService/GetDetails/v1/") {
+			t.Errorf("unexpected endpoint %s", info.Endpoint)
+		}
+	}
+	if failed != 1 || chunks != 3 {
+		t.Errorf("file details requests: %+v", rec.infos)
+	}
+
+	if !strings.Contains(logs.String(), "failed to get file details chunk") {
+		t.Errorf("chunk error is not logged: %s", logs.String())
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+	srv.AddFiles(filedetails.FileDetail{PublishedFileID: 1, Title: "A"})
+
+	// First key is rejected by the server
+	bad := "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"
+	pool := webapi.NewKeyPool(bad, steamtest.Key)
+	rec := &recorder{}
+
+	query := filedetails.New([]uint64{1}, "")
+	query.SetClient(srv.Client())
+	query.SetKeyProvider(pool)
+	query.SetObserver(rec)
+
+	if _, err := query.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.infos) != 2 || rec.infos[0].Status != http.StatusForbidden || rec.infos[1].Retries != 1 {
+		t.Errorf("requests %+v", rec.infos)
+	}
+
+	srv.RateLimit(1)
+	if _, err := query.Get(); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("Get() with rate limited key returned %v", err)
+	}
+	if _, err := query.Get(); !errors.Is(err, webapi.ErrNoKeys) {
+		t.Errorf("Get() with all keys benched returned %v", err)
+	}
+
+	usage := pool.Usage()
+	if usage[0].BenchedUntil.IsZero() || usage[1].BenchedUntil.IsZero() {
+		t.Errorf("keys must be benched: %+v", usage)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	rec := &recorder{}
+
+	query := filedetails.New([]uint64{1}, steamtest.Key)
+	query.SetClient(&http.Client{Transport: failTransport{}})
+	query.SetLogger(logger)
+	query.SetObserver(rec)
+
+	_, err := query.Get()
+	if err == nil {
+		t.Fatal("request must fail")
+	}
+	if strings.Contains(err.Error(), steamtest.Key) {
+		t.Errorf("error contains API key: %v", err)
+	}
+	if !strings.Contains(err.Error(), "key=") || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("error lost details: %v", err)
+	}
+	var uerr *url.Error
+	if !errors.As(err, &uerr) || strings.Contains(uerr.URL, steamtest.Key) {
+		t.Errorf("url.Error is not redacted: %v", err)
+	}
+
+	for _, info := range rec.infos {
+		if strings.Contains(info.Err.Error(), steamtest.Key) {
+			t.Errorf("observed error contains API key: %v", info.Err)
+		}
+	}
+	if strings.Contains(logs.String(), steamtest.Key) {
+		t.Errorf("logs contain API key: %s", logs.String())
+	}
+}
@@ -0,0 +1,220 @@
+package serverlist
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/woozymasta/steam/utils/appid"
+)
+
+// The master server filter has no escaping, so the backslash separator can not be a part of a value.
+// Builder methods replace backslashes in wildcard patterns with "*" and reject them in other values.
+// The first validation error is kept in the Filter and returned by String.
+
+// AppID adds a condition for servers running the application ID.
+func (f *Filter) AppID(id appid.AppID) *Filter {
+	if id == 0 {
+		return f.fail(KeyAppID, "0", "application ID is required")
+	}
+
+	f.Add(KeyAppID, strconv.FormatUint(id.Uint64(), 10))
+	return f
+}
+
+// NotAppID adds a condition for servers not running the application ID.
+func (f *Filter) NotAppID(id appid.AppID) *Filter {
+	if id == 0 {
+		return f.fail(KeyNotAppID, "0", "application ID is required")
+	}
+
+	f.Add(KeyNotAppID, strconv.FormatUint(id.Uint64(), 10))
+	return f
+}
+
+// AnyAppID adds a condition for servers running any of the application IDs as "or" group.
+func (f *Filter) AnyAppID(ids ...appid.AppID) *Filter {
+	if len(ids) == 0 {
+		return f.fail(KeyAppID, "", "at least one application ID is required")
+	}
+
+	items := make([]Expr, len(ids))
+	for i, id := range ids {
+		if id == 0 {
+			return f.fail(KeyAppID, "0", "application ID is required")
+		}
+		items[i] = Cond(KeyAppID, strconv.FormatUint(id.Uint64(), 10))
+	}
+
+	return f.AddGroup(Or(items...))
+}
+
+// Dedicated adds a condition for dedicated servers.
+func (f *Filter) Dedicated() *Filter {
+	f.Add(KeyDedicated, "1")
+	return f
+}
+
+// Secure adds a condition for servers using anti-cheat technologies.
+func (f *Filter) Secure() *Filter {
+	f.Add(KeySecure, "1")
+	return f
+}
+
+// Linux adds a condition for servers running on Linux.
+func (f *Filter) Linux() *Filter {
+	f.Add(KeyLinux, "1")
+	return f
+}
+
+// Password adds a condition for servers with (true) or without (false) password protection.
+func (f *Filter) Password(protected bool) *Filter {
+	f.Add(KeyPassword, boolValue(protected))
+	return f
+}
+
+// CollapseAddr adds a condition to return only one server for each unique IP address.
+func (f *Filter) CollapseAddr() *Filter {
+	f.Add(KeySingleAddr, "1")
+	return f
+}
+
+// Region adds a condition for servers in the region.
+func (f *Filter) Region(region Region) *Filter {
+	if !region.valid() {
+		return f.fail(KeyRegion, strconv.Itoa(int(region)), "unknown region")
+	}
+
+	f.Add(KeyRegion, strconv.Itoa(int(region)))
+	return f
+}
+
+// SteamID adds a condition for the server with the SteamID.
+func (f *Filter) SteamID(id uint64) *Filter {
+	if id == 0 {
+		return f.fail(KeySteamID, "0", "SteamID is required")
+	}
+
+	f.Add(KeySteamID, strconv.FormatUint(id, 10))
+	return f
+}
+
+// Map adds a condition for servers running the map.
+func (f *Filter) Map(name string) *Filter {
+	if err := checkValue(name); err != "" {
+		return f.fail(KeyMap, name, err)
+	}
+
+	f.Add(KeyMap, name)
+	return f
+}
+
+// GameTypeTags adds a condition for servers having all the game type tags.
+func (f *Filter) GameTypeTags(tags ...string) *Filter {
+	return f.addTags(KeyGameType, tags)
+}
+
+// GameTagsAnd adds a condition for servers having all the game type tags.
+func (f *Filter) GameTagsAnd(tags ...string) *Filter {
+	return f.addTags(KeyGameTagsAnd, tags)
+}
+
+// GameTagsNor adds a condition for servers having none of the game type tags.
+func (f *Filter) GameTagsNor(tags ...string) *Filter {
+	return f.addTags(KeyGameTagsNor, tags)
+}
+
+// GameData adds a condition for servers having all the hidden game data tags.
+func (f *Filter) GameData(tags ...string) *Filter {
+	return f.addTags(KeyGameData, tags)
+}
+
+// GameDataOr adds a condition for servers having any of the hidden game data tags.
+func (f *Filter) GameDataOr(tags ...string) *Filter {
+	return f.addTags(KeyGameDataOr, tags)
+}
+
+// NameMatch adds a condition for servers with the name matching the pattern with "*" wildcards.
+func (f *Filter) NameMatch(pattern string) *Filter {
+	if pattern == "" {
+		return f.fail(KeyName, pattern, "value is empty")
+	}
+
+	f.Add(KeyName, strings.ReplaceAll(pattern, "\\", "*"))
+	return f
+}
+
+// GameAddr adds a condition for the server with the IPv4 address, the port is matched only if not zero.
+func (f *Filter) GameAddr(addr netip.AddrPort) *Filter {
+	ip := addr.Addr().Unmap()
+	if !ip.Is4() {
+		return f.fail(KeyGameAddr, addr.String(), "IPv4 address is required")
+	}
+
+	if addr.Port() == 0 {
+		f.Add(KeyGameAddr, ip.String())
+	} else {
+		f.Add(KeyGameAddr, netip.AddrPortFrom(ip, addr.Port()).String())
+	}
+
+	return f
+}
+
+// GameAddrIP adds a condition for servers with the IPv4 address on any port.
+func (f *Filter) GameAddrIP(ip netip.Addr) *Filter {
+	return f.GameAddr(netip.AddrPortFrom(ip, 0))
+}
+
+// Err returns the first error of builder methods.
+func (f *Filter) Err() error {
+	return f.err
+}
+
+// fail keeps the first validation error
+func (f *Filter) fail(key FilterKey, value, reason string) *Filter {
+	if f.err == nil {
+		f.err = fmt.Errorf("invalid %s value %q: %s", key, value, reason)
+	}
+
+	return f
+}
+
+// addTags adds comma separated tags with the key
+func (f *Filter) addTags(key FilterKey, tags []string) *Filter {
+	if len(tags) == 0 {
+		return f.fail(key, "", "at least one tag is required")
+	}
+	for _, tag := range tags {
+		if err := checkValue(tag); err != "" {
+			return f.fail(key, tag, err)
+		}
+		if strings.Contains(tag, ",") {
+			return f.fail(key, tag, "tag must not contain comma")
+		}
+	}
+
+	f.Add(key, strings.Join(tags, ","))
+	return f
+}
+
+// boolValue returns "1" for true and "0" for false
+func boolValue(b bool) string {
+	if b {
+		return "1"
+	}
+
+	return "0"
+}
+
+// checkValue returns the reason if the value can not be used in the filter
+func checkValue(value string) string {
+	switch {
+	case value == "":
+		return "value is empty"
+	case strings.Contains(value, "\\"):
+		return "value must not contain backslash"
+	}
+
+	return ""
+}
@@ -0,0 +1,145 @@
+package serverlist
+
+import (
+	"io"
+	"strconv"
+
+	json "github.com/json-iterator/go"
+)
+
+// ChangeKind is a kind of the server change between two snapshots.
+type ChangeKind string
+
+const (
+	ChangeAppeared    ChangeKind = "appeared"    // Server is only in the new snapshot
+	ChangeDisappeared ChangeKind = "disappeared" // Server is only in the old snapshot
+	ChangeName        ChangeKind = "name"        // Server is renamed
+	ChangeMap         ChangeKind = "map"         // Map is changed
+	ChangeVersion     ChangeKind = "version"     // Game version is changed
+	ChangeMaxPlayers  ChangeKind = "max_players" // Maximum number of players is changed
+	ChangeSteamID     ChangeKind = "steamid"     // SteamID is changed, the server is matched by Addr
+)
+
+// Change is a change of the server between two snapshots.
+type Change struct {
+	Kind   ChangeKind `json:"event"`         // Kind of the change
+	Key    string     `json:"key"`           // Key of the server, "steamid:<SteamID>" or Addr if SteamID is not set
+	Old    string     `json:"old,omitempty"` // Old value, empty for appeared and disappeared servers
+	New    string     `json:"new,omitempty"` // New value, empty for appeared and disappeared servers
+	Server Server     `json:"server"`        // Server from the new snapshot, or from the old one if disappeared
+}
+
+// Changes is a list of changes returned by Diff.
+type Changes []Change
+
+// Diff compares two snapshots of the server list matching servers by SteamID, or by Addr
+// for servers without SteamID. Servers with SteamID not found in the other snapshot are matched
+// by Addr, as some games (e.g. DayZ and Arma 3) get a new anonymous SteamID on every restart,
+// the new SteamID is reported as ChangeSteamID. A server matched by Addr without a new SteamID
+// is reported under the Addr key with no ChangeSteamID. Changes of servers are returned in the order
+// of the new snapshot, followed by disappeared servers in the order of the old snapshot.
+func Diff(before, after Servers) Changes {
+	keys := make(map[string]struct{}, len(after))
+	for i := range after {
+		keys[serverKey(&after[i])] = struct{}{}
+	}
+
+	old := make(map[string]*Server, len(before))
+	byAddr := make(map[string]*Server)
+	for i := range before {
+		key := serverKey(&before[i])
+		old[key] = &before[i]
+		if _, ok := keys[key]; !ok && before[i].Addr != "" && byAddr[before[i].Addr] == nil {
+			byAddr[before[i].Addr] = &before[i]
+		}
+	}
+
+	var changes Changes
+	seen := make(map[string]struct{}, len(after))
+	for i := range after {
+		s := &after[i]
+		key := serverKey(s)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		prev, ok := old[key]
+		if !ok {
+			if prev, ok = byAddr[s.Addr]; ok {
+				delete(byAddr, s.Addr)
+				seen[serverKey(prev)] = struct{}{}
+				if s.SteamID != 0 {
+					changes = changes.add(ChangeSteamID, key,
+						strconv.FormatUint(prev.SteamID, 10), strconv.FormatUint(s.SteamID, 10), s)
+				}
+			}
+		}
+		if !ok {
+			changes = append(changes, Change{Kind: ChangeAppeared, Key: key, Server: *s})
+			continue
+		}
+
+		changes = changes.add(ChangeName, key, prev.Name, s.Name, s)
+		changes = changes.add(ChangeMap, key, prev.Map, s.Map, s)
+		changes = changes.add(ChangeVersion, key, prev.Version, s.Version, s)
+		changes = changes.add(ChangeMaxPlayers, key,
+			strconv.Itoa(int(prev.MaxPlayers)), strconv.Itoa(int(s.MaxPlayers)), s)
+	}
+
+	for i := range before {
+		key := serverKey(&before[i])
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		changes = append(changes, Change{Kind: ChangeDisappeared, Key: key, Server: before[i]})
+	}
+
+	return changes
+}
+
+// Kind returns changes of the kinds.
+func (c Changes) Kind(kinds ...ChangeKind) Changes {
+	var result Changes
+	for _, change := range c {
+		for _, kind := range kinds {
+			if change.Kind == kind {
+				result = append(result, change)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// WriteNDJSON writes changes as events, one JSON object per line.
+func (c Changes) WriteNDJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for i := range c {
+		if err := enc.Encode(&c[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add appends the change if the values differ
+func (c Changes) add(kind ChangeKind, key, old, value string, s *Server) Changes {
+	if old == value {
+		return c
+	}
+
+	return append(c, Change{Kind: kind, Key: key, Old: old, New: value, Server: *s})
+}
+
+// serverKey returns the key identifying the server between snapshots, SteamID or Addr if SteamID is not set
+func serverKey(s *Server) string {
+	if s.SteamID != 0 {
+		return "steamid:" + strconv.FormatUint(s.SteamID, 10)
+	}
+
+	return s.Addr
+}
@@ -0,0 +1,124 @@
+package serverlist_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	json "github.com/json-iterator/go"
+	"github.com/woozymasta/steam/serverlist"
+)
+
+func TestDiff(t *testing.T) {
+	before := serverlist.Servers{
+		{SteamID: 1, Addr: "10.0.0.1:27016", Name: "DayZ 1", Map: "chernarusplus", Version: "1.25", MaxPlayers: 60},
+		{SteamID: 2, Addr: "10.0.0.1:27017", Name: "DayZ 2", Map: "enoch", Version: "1.25", MaxPlayers: 60},
+		{Addr: "10.0.0.2:27016", Name: "No SteamID", Map: "livonia", Players: 3},
+	}
+	after := serverlist.Servers{
+		{SteamID: 3, Addr: "10.0.0.3:27016", Name: "DayZ 3"},
+		{SteamID: 1, Addr: "10.0.0.1:27018", Name: "DayZ One", Map: "chernarusplus", Version: "1.26", MaxPlayers: 80},
+		{Addr: "10.0.0.2:27016", Name: "No SteamID", Map: "sakhal", Players: 10},
+	}
+
+	changes := serverlist.Diff(before, after)
+	expected := []struct {
+		kind     serverlist.ChangeKind
+		key      string
+		from, to string
+	}{
+		{serverlist.ChangeAppeared, "steamid:3", "", ""},
+		{serverlist.ChangeName, "steamid:1", "DayZ 1", "DayZ One"},
+		{serverlist.ChangeVersion, "steamid:1", "1.25", "1.26"},
+		{serverlist.ChangeMaxPlayers, "steamid:1", "60", "80"},
+		{serverlist.ChangeMap, "10.0.0.2:27016", "livonia", "sakhal"},
+		{serverlist.ChangeDisappeared, "steamid:2", "", ""},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Diff() returned %d changes: %+v", len(changes), changes)
+	}
+	for i, e := range expected {
+		c := changes[i]
+		if c.Kind != e.kind || c.Key != e.key || c.Old != e.from || c.New != e.to {
+			t.Errorf("change %d = %s %s %q -> %q, expected %s %s %q -> %q",
+				i, c.Kind, c.Key, c.Old, c.New, e.kind, e.key, e.from, e.to)
+		}
+	}
+	if changes[1].Server.Addr != "10.0.0.1:27018" || changes[5].Server.Name != "DayZ 2" {
+		t.Errorf("changes refer to wrong servers: %+v", changes)
+	}
+
+	if got := changes.Kind(serverlist.ChangeAppeared, serverlist.ChangeVersion); len(got) != 2 {
+		t.Errorf("Kind() returned %d changes", len(got))
+	}
+	if got := serverlist.Diff(after, after); len(got) != 0 {
+		t.Errorf("Diff() of the same snapshot = %+v", got)
+	}
+}
+
+func TestDiffSteamIDChange(t *testing.T) {
+	before := serverlist.Servers{
+		{SteamID: 90200000000000001, Addr: "10.0.0.1:27016", Name: "DayZ 1", Map: "chernarusplus"},
+		{SteamID: 90200000000000002, Addr: "10.0.0.1:27017", Name: "DayZ 2"},
+		{SteamID: 90200000000000005, Addr: "10.0.0.1:27019", Name: "DayZ 5", Map: "sakhal"},
+	}
+	after := serverlist.Servers{
+		{SteamID: 90200000000000003, Addr: "10.0.0.1:27016", Name: "DayZ 1", Map: "enoch"},
+		{SteamID: 90200000000000004, Addr: "10.0.0.1:27018", Name: "DayZ 4"},
+		// SteamID is missing in the response, the server is matched by Addr without SteamID change
+		{Addr: "10.0.0.1:27019", Name: "DayZ 5", Map: "livonia"},
+	}
+
+	changes := serverlist.Diff(before, after)
+	expected := []struct {
+		kind     serverlist.ChangeKind
+		key      string
+		from, to string
+	}{
+		{serverlist.ChangeSteamID, "steamid:90200000000000003", "90200000000000001", "90200000000000003"},
+		{serverlist.ChangeMap, "steamid:90200000000000003", "chernarusplus", "enoch"},
+		{serverlist.ChangeAppeared, "steamid:90200000000000004", "", ""},
+		{serverlist.ChangeMap, "10.0.0.1:27019", "sakhal", "livonia"},
+		{serverlist.ChangeDisappeared, "steamid:90200000000000002", "", ""},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Diff() returned %d changes: %+v", len(changes), changes)
+	}
+	for i, e := range expected {
+		c := changes[i]
+		if c.Kind != e.kind || c.Key != e.key || c.Old != e.from || c.New != e.to {
+			t.Errorf("change %d = %s %s %q -> %q, expected %s %s %q -> %q",
+				i, c.Kind, c.Key, c.Old, c.New, e.kind, e.key, e.from, e.to)
+		}
+	}
+}
+
+func TestChangesNDJSON(t *testing.T) {
+	changes := serverlist.Diff(
+		serverlist.Servers{{SteamID: 1, Version: "1.25"}},
+		serverlist.Servers{{SteamID: 1, Version: "1.26"}, {SteamID: 2, Name: "New"}},
+	)
+
+	var buf bytes.Buffer
+	if err := changes.WriteNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("WriteNDJSON() wrote %d lines:\n%s", len(lines), buf.String())
+	}
+
+	var event serverlist.Change
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Kind != serverlist.ChangeVersion || event.Old != "1.25" || event.New != "1.26" || event.Server.SteamID != 1 {
+		t.Errorf("decoded event %+v", event)
+	}
+	if !strings.Contains(lines[1], `"event":"appeared"`) {
+		t.Errorf("unexpected event %s", lines[1])
+	}
+}
@@ -0,0 +1,206 @@
+package serverlist
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Operator is a logical operator of a filter group.
+type Operator string
+
+const (
+	// OpAnd matches servers matching all conditions of the group.
+	OpAnd Operator = "and"
+	// OpOr matches servers matching any condition of the group.
+	OpOr Operator = "or"
+	// OpNor matches servers matching none of the conditions of the group.
+	OpNor Operator = "nor"
+	// OpNand matches servers not matching all conditions of the group together.
+	OpNand Operator = "nand"
+)
+
+// Expr is a node of the filter expression: Condition, *Group or *Filter.
+// A nested group or filter counts as a single condition of the parent group.
+type Expr interface {
+	encode() (string, error)
+	canonical() Expr
+}
+
+// Condition is a single key\value filter condition.
+type Condition struct {
+	Key   FilterKey // Filter key
+	Value string    // Raw value
+}
+
+// Cond returns the key\value condition.
+func Cond(key FilterKey, value string) Condition {
+	return Condition{Key: key, Value: value}
+}
+
+// Group is a group of expressions combined with the operator, serialized as "op\count\...".
+type Group struct {
+	Items []Expr   // Conditions and nested groups
+	Op    Operator // Logical operator
+}
+
+// And returns the group matching all items.
+func And(items ...Expr) *Group {
+	return &Group{Op: OpAnd, Items: items}
+}
+
+// Or returns the group matching any of the items.
+func Or(items ...Expr) *Group {
+	return &Group{Op: OpOr, Items: items}
+}
+
+// Nor returns the group matching none of the items.
+func Nor(items ...Expr) *Group {
+	return &Group{Op: OpNor, Items: items}
+}
+
+// Nand returns the group not matching all of the items together.
+func Nand(items ...Expr) *Group {
+	return &Group{Op: OpNand, Items: items}
+}
+
+// AddGroup adds the group combined with other conditions of the Filter.
+func (f *Filter) AddGroup(group *Group) *Filter {
+	f.groups = append(f.groups, group)
+	return f
+}
+
+// encode implements Expr
+func (c Condition) encode() (string, error) {
+	if c.Key == "" {
+		return "", fmt.Errorf("empty filter key")
+	}
+
+	return string(c.Key) + "\\" + c.Value, nil
+}
+
+// canonical implements Expr
+func (c Condition) canonical() Expr {
+	return c
+}
+
+// encode implements Expr
+func (g *Group) encode() (string, error) {
+	if g == nil || len(g.Items) == 0 {
+		return "", fmt.Errorf("empty filter group")
+	}
+
+	switch g.Op {
+	case OpAnd, OpOr, OpNor, OpNand:
+	default:
+		return "", fmt.Errorf("unknown filter group operator %q", g.Op)
+	}
+
+	parts := make([]string, len(g.Items))
+	for i, item := range g.Items {
+		if item == nil {
+			return "", fmt.Errorf("nil item in %s group", g.Op)
+		}
+
+		part, err := item.encode()
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+
+	return fmt.Sprintf("%s\\%d\\%s", g.Op, len(parts), strings.Join(parts, "\\")), nil
+}
+
+// canonical implements Expr
+func (g *Group) canonical() Expr {
+	if g == nil {
+		return g
+	}
+
+	items := make([]Expr, len(g.Items))
+	for i, item := range g.Items {
+		if item != nil {
+			item = item.canonical()
+		}
+		items[i] = item
+	}
+	sortExprs(items)
+
+	return &Group{Op: g.Op, Items: items}
+}
+
+// encode implements Expr, the filter of several items is encoded as "and" group
+func (f *Filter) encode() (string, error) {
+	items, err := f.items()
+	if err != nil {
+		return "", err
+	}
+
+	switch len(items) {
+	case 0:
+		return "", fmt.Errorf("empty filter")
+	case 1:
+		return items[0], nil
+	}
+
+	return fmt.Sprintf("and\\%d\\%s", len(items), strings.Join(items, "\\")), nil
+}
+
+// canonical implements Expr
+func (f *Filter) canonical() Expr {
+	c := f.clone()
+	sort.Strings(c.conditions)
+	sort.Strings(c.norConditions)
+	sort.Strings(c.nandConditions)
+	for i, g := range c.groups {
+		c.groups[i], _ = g.canonical().(*Group)
+	}
+	sort.SliceStable(c.groups, func(i, j int) bool {
+		return exprKey(c.groups[i]) < exprKey(c.groups[j])
+	})
+
+	return c
+}
+
+// items returns serialized top level conditions and groups of the filter
+func (f *Filter) items() ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	items := append([]string(nil), f.conditions...)
+	if len(f.norConditions) > 0 {
+		items = append(items, fmt.Sprintf("nor\\%d\\%s", len(f.norConditions), strings.Join(f.norConditions, "\\")))
+	}
+	if len(f.nandConditions) > 0 {
+		items = append(items, fmt.Sprintf("nand\\%d\\%s", len(f.nandConditions), strings.Join(f.nandConditions, "\\")))
+	}
+
+	for _, g := range f.groups {
+		item, err := g.encode()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// sortExprs sorts expressions by the serialized form
+func sortExprs(items []Expr) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return exprKey(items[i]) < exprKey(items[j])
+	})
+}
+
+// exprKey returns serialized expression used to sort, invalid expressions are sorted first
+func exprKey(e Expr) string {
+	if e == nil {
+		return ""
+	}
+
+	s, _ := e.encode()
+	return s
+}
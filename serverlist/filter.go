@@ -94,14 +94,36 @@ const (
 	// KeyGameAddr filters for servers with a specific IP address (port is optional).
 	//  - ip: Specific IP address (e.g., "192.168.1.1") with optional port (e.g., "192.168.1.1:27015")
 	KeyGameAddr FilterKey = "gameaddr"
+
+	// KeyGameTagsAnd filters for servers with all of the game type tags.
+	//  - str: Comma separated tags (e.g., "secure,valve_ds") use in CS2
+	KeyGameTagsAnd FilterKey = "gametagsand"
+
+	// KeyGameTagsNor filters for servers with none of the game type tags.
+	//  - str: Comma separated tags (e.g., "external,modded")
+	KeyGameTagsNor FilterKey = "gametagsnor"
+
+	// KeyGameDataOr filters for servers with any of the hidden game data tags.
+	//  - str: Comma separated hidden tags (e.g., "mp,ptrak") use in Rust
+	KeyGameDataOr FilterKey = "gamedataor"
+
+	// KeyRegion filters for servers in the region.
+	//  - int: Region code (e.g., "3" for Europe), see Region
+	KeyRegion FilterKey = "region"
+
+	// KeySteamID filters for the server with the SteamID.
+	//  - id: Server SteamID as decimal number (e.g., "90200000000000000")
+	KeySteamID FilterKey = "steamid"
 )
 
 // Filter is used to build filter conditions for API requests.
-// It supports standard, NOR, and NAND filter conditions.
+// It supports standard, NOR, and NAND filter conditions and nested groups of conditions.
 type Filter struct {
+	err            error
 	conditions     []string
 	norConditions  []string
 	nandConditions []string
+	groups         []*Group
 }
 
 // Add adds a standard filter condition to the Filter.
@@ -159,23 +181,65 @@ func (f *Filter) RemoveNand(key FilterKey, value string) {
 }
 
 // String constructs the filter string from the Filter's conditions.
-// It ensures that both NOR and NAND conditions are not used simultaneously.
-// Returns the constructed filter string or an error if validation fails.
+// Standard conditions go first, then NOR and NAND conditions and groups added with AddGroup.
+// Returns the constructed filter string or an error if validation fails, e.g. of an empty group.
 func (f *Filter) String() (string, error) {
-	if len(f.norConditions) > 0 && len(f.nandConditions) > 0 {
-		return "", fmt.Errorf("cannot use both nor and nand conditions in the same filter")
+	items, err := f.items()
+	if err != nil {
+		return "", err
 	}
 
-	var parts []string
-	if len(f.conditions) > 0 {
-		parts = append(parts, strings.Join(f.conditions, "\\"))
+	return strings.Join(items, "\\"), nil
+}
+
+// has reports whether the key is used in any condition of the filter, including groups
+func (f *Filter) has(key FilterKey) bool {
+	prefix := string(key) + "\\"
+	for _, conditions := range [][]string{f.conditions, f.norConditions, f.nandConditions} {
+		for _, condition := range conditions {
+			if strings.HasPrefix(condition, prefix) {
+				return true
+			}
+		}
 	}
-	if len(f.norConditions) > 0 {
-		parts = append(parts, fmt.Sprintf("nor\\%d\\%s", len(f.norConditions), strings.Join(f.norConditions, "\\")))
+
+	for _, g := range f.groups {
+		if exprHas(g, key) {
+			return true
+		}
 	}
-	if len(f.nandConditions) > 0 {
-		parts = append(parts, fmt.Sprintf("nand\\%d\\%s", len(f.nandConditions), strings.Join(f.nandConditions, "\\")))
+
+	return false
+}
+
+// exprHas reports whether the key is used in the expression
+func exprHas(e Expr, key FilterKey) bool {
+	switch e := e.(type) {
+	case Condition:
+		return e.Key == key
+	case *Group:
+		if e == nil {
+			return false
+		}
+		for _, item := range e.Items {
+			if exprHas(item, key) {
+				return true
+			}
+		}
+	case *Filter:
+		return e != nil && e.has(key)
 	}
 
-	return strings.Join(parts, "\\"), nil
+	return false
+}
+
+// clone returns a copy of the filter
+func (f *Filter) clone() *Filter {
+	return &Filter{
+		err:            f.err,
+		conditions:     append([]string(nil), f.conditions...),
+		norConditions:  append([]string(nil), f.norConditions...),
+		nandConditions: append([]string(nil), f.nandConditions...),
+		groups:         append([]*Group(nil), f.groups...),
+	}
 }
@@ -0,0 +1,234 @@
+package serverlist_test
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/utils/appid"
+)
+
+func TestBuilder(t *testing.T) {
+	filter := (&serverlist.Filter{}).
+		AppID(appid.DayZ).
+		Dedicated().
+		Secure().
+		Linux().
+		Map("chernarusplus").
+		GameTypeTags("battleye", "lqs0").
+		NameMatch(`*official\*`).
+		GameAddr(netip.MustParseAddrPort("10.0.0.1:0"))
+	filter.AddNor(serverlist.KeyGameType, "external")
+
+	got, err := filter.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `appid\221100\dedicated\1\secure\1\linux\1\map\chernarusplus\gametype\battleye,lqs0` +
+		`\name_match\*official**\gameaddr\10.0.0.1\nor\1\gametype\external`
+	if got != want {
+		t.Errorf("String() = %q, expected %q", got, want)
+	}
+
+	port, _ := (&serverlist.Filter{}).GameAddr(netip.MustParseAddrPort("[::ffff:10.0.0.1]:2302")).String()
+	if port != `gameaddr\10.0.0.1:2302` {
+		t.Errorf("GameAddr() = %q", port)
+	}
+}
+
+func TestBuilderKeys(t *testing.T) {
+	filter := (&serverlist.Filter{}).
+		AnyAppID(appid.Rust, appid.DayZ).
+		NotAppID(appid.CounterStrike2).
+		GameTagsAnd("secure", "valve_ds").
+		GameTagsNor("external").
+		GameData("a").
+		GameDataOr("mp", "ptrak").
+		Password(false).
+		CollapseAddr().
+		Region(serverlist.RegionEurope).
+		SteamID(90200000000000001).
+		GameAddrIP(netip.MustParseAddr("10.0.0.1"))
+
+	got, err := filter.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `napp\730\gametagsand\secure,valve_ds\gametagsnor\external\gamedata\a\gamedataor\mp,ptrak` +
+		`\password\0\collapse_addr_hash\1\region\3\steamid\90200000000000001\gameaddr\10.0.0.1` +
+		`\or\2\appid\252490\appid\221100`
+	if got != want {
+		t.Errorf("String() = %q, expected %q", got, want)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	cases := map[string]*serverlist.Filter{
+		"appid":     (&serverlist.Filter{}).AppID(0),
+		"map":       (&serverlist.Filter{}).Map(`bad\map`),
+		"empty map": (&serverlist.Filter{}).Map(""),
+		"tags":      (&serverlist.Filter{}).GameTypeTags(),
+		"tag comma": (&serverlist.Filter{}).GameTypeTags("a,b"),
+		"name":      (&serverlist.Filter{}).NameMatch(""),
+		"ipv6":      (&serverlist.Filter{}).GameAddr(netip.MustParseAddrPort("[::1]:1")),
+		"zero addr": (&serverlist.Filter{}).GameAddr(netip.AddrPort{}),
+		"chain":     (&serverlist.Filter{}).Map("").AppID(appid.DayZ).Dedicated(),
+		"region":    (&serverlist.Filter{}).Region(42),
+		"any appid": (&serverlist.Filter{}).AnyAppID(),
+		"not appid": (&serverlist.Filter{}).NotAppID(0),
+		"steamid":   (&serverlist.Filter{}).SteamID(0),
+		"data tags": (&serverlist.Filter{}).GameDataOr("a", ""),
+	}
+
+	for name, filter := range cases {
+		if filter.Err() == nil {
+			t.Errorf("%s: Err() is nil", name)
+		}
+		if _, err := filter.String(); err == nil {
+			t.Errorf("%s: String() must fail", name)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	cases := map[string]string{
+		`\appid\221100\gametype\battleye\nor\1\gametype\external`: `appid\221100\gametype\battleye\nor\1\gametype\external`,
+		`appid\221100\nor\1\map\a\nor\1\map\b`:                    `appid\221100\nor\2\map\a\map\b`,
+		`\and\2\secure\1\dedicated\1\appid\730`:                   `secure\1\dedicated\1\appid\730`,
+		`\nand\2\map\a\linux\1\`:                                  `nand\2\map\a\linux\1`,
+		``:                                                        ``,
+		`\nor\1\nor\1\map\a`:                                      `nor\1\nor\1\map\a`,
+		`\nand\1\map\a\nand\1\map\b`:                              `nand\1\map\a\nand\1\map\b`,
+		`\nor\1\map\a\nand\1\map\b`:                               `nor\1\map\a\nand\1\map\b`,
+		`\or\2\map\a\map\b`:                                       `or\2\map\a\map\b`,
+		`\appid\730\nand\1\and\1\a\1`:                             `appid\730\nand\1\and\1\a\1`,
+		`\or\2\and\2\map\a\linux\1\nor\1\secure\1`:                `or\2\and\2\map\a\linux\1\nor\1\secure\1`,
+		`\appid\730\map\`:                                         `appid\730\map\`,
+		`\appid\730\`:                                             `appid\730`,
+	}
+
+	for s, want := range cases {
+		f, err := serverlist.ParseFilter(s)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", s, err)
+			continue
+		}
+		if got, _ := f.String(); got != want {
+			t.Errorf("ParseFilter(%q).String() = %q, expected %q", s, got, want)
+		}
+	}
+}
+
+func TestParseFilterRoundTrip(t *testing.T) {
+	f := &serverlist.Filter{}
+	f.Add(serverlist.KeyAppID, "221100")
+	f.Add(serverlist.KeyMap, "")
+	f.AddNor(serverlist.KeyGameType, "")
+
+	s, err := f.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := serverlist.ParseFilter(s)
+	if err != nil {
+		t.Fatalf("ParseFilter(%q): %v", s, err)
+	}
+	if got, _ := parsed.String(); got != s {
+		t.Errorf("ParseFilter(%q).String() = %q", s, got)
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	cases := map[string]int{
+		`\appid`:                       1,
+		`\appid\1\\1`:                  9,
+		`\nor\x\map\a`:                 5,
+		`\nor\2\map\a`:                 11,
+		`\appid\730\nand\0\appid\1`:    16,
+		`\appid\730\nand\-1\appid\1\x`: 16,
+	}
+
+	for s, offset := range cases {
+		_, err := serverlist.ParseFilter(s)
+		var perr *serverlist.ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("ParseFilter(%q) = %v, expected ParseError", s, err)
+			continue
+		}
+		if perr.Offset != offset {
+			t.Errorf("ParseFilter(%q) offset = %d, expected %d (%v)", s, perr.Offset, offset, err)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	a, _ := serverlist.ParseFilter(`\secure\1\appid\730\nor\2\map\b\map\a`)
+	b, _ := serverlist.ParseFilter(`\appid\730\nor\1\map\a\secure\1\nor\1\map\b`)
+
+	ca, err := a.Canonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, _ := b.Canonical()
+	if ca != cb || ca != `appid\730\secure\1\nor\2\map\a\map\b` {
+		t.Errorf("Canonical() = %q and %q", ca, cb)
+	}
+	if s, _ := a.String(); s != `secure\1\appid\730\nor\2\map\b\map\a` {
+		t.Errorf("Canonical() changed the filter: %q", s)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	filter := (&serverlist.Filter{}).AppID(appid.DayZ)
+	filter.AddNor(serverlist.KeyGameType, "external")
+	filter.AddNand(serverlist.KeyMap, "enoch")
+	filter.AddGroup(serverlist.Or(
+		serverlist.Cond(serverlist.KeyMap, "chernarusplus"),
+		serverlist.And(
+			serverlist.Cond(serverlist.KeyMap, "enoch"),
+			serverlist.Nor(serverlist.Cond(serverlist.KeyNoPlayers, "1")),
+		),
+		(&serverlist.Filter{}).Linux().Secure(),
+	))
+
+	got, err := filter.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `appid\221100\nor\1\gametype\external\nand\1\map\enoch` +
+		`\or\3\map\chernarusplus\and\2\map\enoch\nor\1\noplayers\1\and\2\linux\1\secure\1`
+	if got != want {
+		t.Errorf("String() = %q, expected %q", got, want)
+	}
+
+	parsed, err := serverlist.ParseFilter(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := parsed.String(); s != want {
+		t.Errorf("ParseFilter().String() = %q, expected %q", s, want)
+	}
+
+	a, _ := serverlist.ParseFilter(`\or\2\map\b\and\2\linux\1\map\a\appid\1`)
+	b, _ := serverlist.ParseFilter(`\appid\1\or\2\and\2\map\a\linux\1\map\b`)
+	ca, _ := a.Canonical()
+	cb, _ := b.Canonical()
+	if ca != cb {
+		t.Errorf("Canonical() = %q and %q", ca, cb)
+	}
+
+	for name, group := range map[string]*serverlist.Group{
+		"empty":  serverlist.Nor(),
+		"nested": serverlist.Or(serverlist.Cond(serverlist.KeyMap, "a"), serverlist.And()),
+		"filter": serverlist.Or((&serverlist.Filter{}).Map("")),
+		"op":     {Op: "xor", Items: []serverlist.Expr{serverlist.Cond(serverlist.KeyMap, "a")}},
+	} {
+		if _, err := (&serverlist.Filter{}).AddGroup(group).String(); err == nil {
+			t.Errorf("%s group: String() must fail", name)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package serverlist
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/woozymasta/steam/utils/appid"
+)
+
+// DayZTags is the DayZ server game type decoded from tags like
+// "battleye,no3rd,external,privHive,mod,lqs0,etm2.300000,entm3.000000,15:22".
+type DayZTags struct {
+	Time                  string   // In-game time "HH:MM"
+	Unknown               []string // Tags not recognized by the decoder
+	TimeAcceleration      float64  // Day time acceleration (etm)
+	NightTimeAcceleration float64  // Night time acceleration (entm)
+	QueueSize             int      // Login queue size (lqs)
+	BattlEye              bool     // BattlEye is enabled
+	FirstPerson           bool     // Third person view is disabled (no3rd)
+	External              bool     // Community server (external)
+	PrivateHive           bool     // Private hive (privHive)
+	Shard                 bool     // Official shard server
+	Modded                bool     // Server requires mods (mod)
+	DLC                   bool     // Server requires DLC (isDLC)
+}
+
+// MissionState is the Arma 3 server state.
+type MissionState int
+
+const (
+	MissionNone       MissionState = iota // No mission
+	MissionSelecting                      // Selecting mission
+	MissionEditing                        // Editing mission
+	MissionAssigning                      // Assigning roles
+	MissionSending                        // Sending mission
+	MissionLoading                        // Loading game
+	MissionBriefing                       // Briefing
+	MissionPlaying                        // Playing
+	MissionDebriefing                     // Debriefing
+	MissionAborted                        // Mission aborted
+)
+
+// missionStateNames are names of known mission states
+var missionStateNames = [...]string{
+	"none", "selecting", "editing", "assigning", "sending", "loading", "briefing", "playing", "debriefing", "aborted",
+}
+
+// String returns the mission state name.
+func (m MissionState) String() string {
+	if m >= 0 && int(m) < len(missionStateNames) {
+		return missionStateNames[m]
+	}
+
+	return strconv.Itoa(int(m))
+}
+
+// Arma3Tags is the Arma 3 server game type decoded from tags like
+// "bt,r218,n152405,s7,i2,mf,lf,vt,dt,tcoop,g65545,c0-52,pw,h8d1a2c3b,e15,j0,k0".
+// Each tag is a letter with the value, booleans are encoded with "t" and "f".
+type Arma3Tags struct {
+	Version          string       // Required game version (r218 is "2.18")
+	GameType         string       // Mission game type (t), e.g. "coop"
+	Platform         string       // Server platform (p), "w" for Windows and "l" for Linux
+	Country          string       // Server country code (o)
+	ContentHash      string       // Hash of the loaded content (h), hexadecimal
+	Unknown          []string     // Tags not recognized by the decoder
+	Build            int          // Required build number (n)
+	State            MissionState // Mission state (s)
+	Difficulty       int          // Difficulty (i)
+	Language         int          // Server language (g)
+	TimeLeft         int          // Mission time left in minutes (e)
+	FilePatching     int          // Allowed file patching (f), 0 none, 1 headless clients, 2 all clients
+	Param1           int          // Mission parameter 1 (j)
+	Param2           int          // Mission parameter 2 (k)
+	BattlEye         bool         // BattlEye is enabled (b)
+	Locked           bool         // Server is locked (l)
+	VerifySignatures bool         // Signatures of addons are verified (v)
+	Dedicated        bool         // Dedicated server (d)
+	EqualModRequired bool         // Clients must run the same mods as the server (m)
+}
+
+// Tags returns the game type decoded by the decoder of the server application,
+// *DayZTags for DayZ and DayZ Experimental, *Arma3Tags for Arma 3, or nil for other games.
+func (s *Server) Tags() any {
+	switch appid.AppID(s.Appid) {
+	case appid.DayZ, appid.DayZExp:
+		tags := ParseDayZTags(s.GameType)
+		return &tags
+	case appid.Arma3:
+		tags := ParseArma3Tags(s.GameType)
+		return &tags
+	}
+
+	return nil
+}
+
+// ParseDayZTags decodes DayZ server game type tags.
+func ParseDayZTags(gt GameType) DayZTags {
+	var tags DayZTags
+
+	for _, tag := range gt {
+		switch {
+		case tag == "":
+		case tag == "battleye":
+			tags.BattlEye = true
+		case tag == "no3rd":
+			tags.FirstPerson = true
+		case tag == "external":
+			tags.External = true
+		case tag == "privHive":
+			tags.PrivateHive = true
+		case tag == "shard":
+			tags.Shard = true
+		case tag == "mod":
+			tags.Modded = true
+		case tag == "isDLC":
+			tags.DLC = true
+		case isClock(tag):
+			tags.Time = tag
+		case parseTagInt(tag, "lqs", &tags.QueueSize):
+		case parseTagFloat(tag, "entm", &tags.NightTimeAcceleration):
+		case parseTagFloat(tag, "etm", &tags.TimeAcceleration):
+		default:
+			tags.Unknown = append(tags.Unknown, tag)
+		}
+	}
+
+	return tags
+}
+
+// ParseArma3Tags decodes Arma 3 server game type tags.
+func ParseArma3Tags(gt GameType) Arma3Tags {
+	var tags Arma3Tags
+
+	for _, tag := range gt {
+		if tag == "" {
+			continue
+		}
+
+		value := tag[1:]
+		known := true
+		switch tag[0] {
+		case 'b':
+			known = parseTagBool(value, &tags.BattlEye)
+		case 'l':
+			known = parseTagBool(value, &tags.Locked)
+		case 'v':
+			known = parseTagBool(value, &tags.VerifySignatures)
+		case 'd':
+			known = parseTagBool(value, &tags.Dedicated)
+		case 'm':
+			known = parseTagBool(value, &tags.EqualModRequired)
+		case 'r':
+			tags.Version, known = arma3Version(value)
+		case 'n':
+			known = parseTagInt(tag, "n", &tags.Build)
+		case 's':
+			var state int
+			known = parseTagInt(tag, "s", &state)
+			tags.State = MissionState(state)
+		case 'i':
+			known = parseTagInt(tag, "i", &tags.Difficulty)
+		case 'g':
+			known = parseTagInt(tag, "g", &tags.Language)
+		case 'e':
+			known = parseTagInt(tag, "e", &tags.TimeLeft)
+		case 'f':
+			known = parseTagInt(tag, "f", &tags.FilePatching)
+		case 'j':
+			known = parseTagInt(tag, "j", &tags.Param1)
+		case 'k':
+			known = parseTagInt(tag, "k", &tags.Param2)
+		case 'h':
+			tags.ContentHash = value
+		case 't':
+			tags.GameType = value
+		case 'p':
+			tags.Platform = value
+		case 'o':
+			tags.Country = value
+		default:
+			known = false
+		}
+
+		if !known {
+			tags.Unknown = append(tags.Unknown, tag)
+		}
+	}
+
+	return tags
+}
+
+// arma3Version decodes the version "218" as "2.18"
+func arma3Version(value string) (string, bool) {
+	if len(value) < 2 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(value); err != nil {
+		return "", false
+	}
+
+	return value[:1] + "." + value[1:], true
+}
+
+// parseTagInt parses the integer tag with the prefix
+func parseTagInt(tag, prefix string, v *int) bool {
+	if !strings.HasPrefix(tag, prefix) {
+		return false
+	}
+
+	n, err := strconv.Atoi(tag[len(prefix):])
+	if err != nil {
+		return false
+	}
+
+	*v = n
+	return true
+}
+
+// parseTagFloat parses the float tag with the prefix
+func parseTagFloat(tag, prefix string, v *float64) bool {
+	if !strings.HasPrefix(tag, prefix) {
+		return false
+	}
+
+	f, err := strconv.ParseFloat(tag[len(prefix):], 64)
+	if err != nil {
+		return false
+	}
+
+	*v = f
+	return true
+}
+
+// parseTagBool parses "t" and "f" values
+func parseTagBool(value string, v *bool) bool {
+	switch value {
+	case "t":
+		*v = true
+	case "f":
+		*v = false
+	default:
+		return false
+	}
+
+	return true
+}
+
+// isClock reports whether the tag is the "HH:MM" time
+func isClock(tag string) bool {
+	if len(tag) != 5 || tag[2] != ':' {
+		return false
+	}
+
+	for _, i := range []int{0, 1, 3, 4} {
+		if tag[i] < '0' || tag[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
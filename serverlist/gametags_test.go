@@ -0,0 +1,57 @@
+package serverlist_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/utils/appid"
+)
+
+func TestDayZTags(t *testing.T) {
+	gt := serverlist.GameType(strings.Split("battleye,no3rd,external,privHive,mod,lqs12,etm2.300000,entm3.000000,15:22,isDLC,foo", ","))
+	got := serverlist.ParseDayZTags(gt)
+
+	want := serverlist.DayZTags{
+		Time: "15:22", Unknown: []string{"foo"}, TimeAcceleration: 2.3, NightTimeAcceleration: 3, QueueSize: 12,
+		BattlEye: true, FirstPerson: true, External: true, PrivateHive: true, Modded: true, DLC: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDayZTags() = %+v, expected %+v", got, want)
+	}
+}
+
+func TestArma3Tags(t *testing.T) {
+	gt := serverlist.GameType(strings.Split("bt,r218,n152405,s7,i2,mt,lf,vt,dt,tcoop,g65545,c0-52,pw,h8d1a2c3b,oDE,e15,j3,k-1,f1", ","))
+	got := serverlist.ParseArma3Tags(gt)
+
+	want := serverlist.Arma3Tags{
+		Version: "2.18", GameType: "coop", Platform: "w", Country: "DE", ContentHash: "8d1a2c3b", Unknown: []string{"c0-52"},
+		Build: 152405, State: serverlist.MissionPlaying, Difficulty: 2, Language: 65545, TimeLeft: 15, FilePatching: 1,
+		Param1: 3, Param2: -1, BattlEye: true, VerifySignatures: true, Dedicated: true, EqualModRequired: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseArma3Tags() = %+v, expected %+v", got, want)
+	}
+	if got.State.String() != "playing" || serverlist.MissionState(42).String() != "42" {
+		t.Errorf("MissionState.String() = %s", got.State)
+	}
+}
+
+func TestServerTags(t *testing.T) {
+	dayz := serverlist.Server{Appid: appid.DayZ.Uint64(), GameType: serverlist.GameType{"battleye", "lqs3"}}
+	if tags, ok := dayz.Tags().(*serverlist.DayZTags); !ok || !tags.BattlEye || tags.QueueSize != 3 {
+		t.Errorf("DayZ Tags() = %#v", dayz.Tags())
+	}
+
+	arma := serverlist.Server{Appid: appid.Arma3.Uint64(), GameType: serverlist.GameType{"bt", "r216"}}
+	if tags, ok := arma.Tags().(*serverlist.Arma3Tags); !ok || !tags.BattlEye || tags.Version != "2.16" {
+		t.Errorf("Arma 3 Tags() = %#v", arma.Tags())
+	}
+
+	cs := serverlist.Server{Appid: appid.CounterStrike2.Uint64(), GameType: serverlist.GameType{"secure"}}
+	if cs.Tags() != nil {
+		t.Errorf("CS2 Tags() = %#v, expected nil", cs.Tags())
+	}
+}
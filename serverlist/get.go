@@ -1,27 +1,38 @@
 package serverlist
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 
 	json "github.com/json-iterator/go"
+	"github.com/woozymasta/steam/utils/webapi"
 )
 
 // SteamQuery provides an interface for interacting with the Steam API.
 type SteamQuery struct {
-	client *http.Client
-	key    string
-	limit  int
+	observer webapi.Observer
+	keys     webapi.KeyProvider
+	client   *http.Client
+	logger   *slog.Logger
+	baseURL  string
+	key      string
+	timeout  time.Duration
+	limit    int
 }
 
 // New creates a new instance of SteamQuery with the provided API key.
 // It initializes the HTTP client and sets the default limit for server retrieval.
 func New(apiKey string) *SteamQuery {
 	return &SteamQuery{
-		key:    apiKey,
-		client: &http.Client{},
-		limit:  DefaultLimit,
+		key:     apiKey,
+		client:  &http.Client{},
+		baseURL: baseURL,
+		timeout: DefaultTimeout,
+		limit:   DefaultLimit,
 	}
 }
 
@@ -31,39 +42,142 @@ func (sq *SteamQuery) SetKey(key string) {
 	sq.key = key
 }
 
+// SetKeyProvider sets the provider of API keys, e.g. webapi.KeyPool to rotate several keys.
+// If set, it is used instead of the key passed to New or SetKey.
+func (sq *SteamQuery) SetKeyProvider(provider webapi.KeyProvider) {
+	sq.keys = provider
+}
+
+// SetClient sets the HTTP client used for requests to the Steam API.
+// This allows to set timeouts or a custom transport.
+func (sq *SteamQuery) SetClient(client *http.Client) {
+	if client == nil {
+		client = &http.Client{}
+	}
+	sq.client = client
+}
+
+// SetBaseURL sets the GetServerList endpoint URL, e.g. for a local stand-in or a proxy.
+// An empty URL resets the default Steam API endpoint.
+func (sq *SteamQuery) SetBaseURL(endpoint string) {
+	if endpoint == "" {
+		endpoint = baseURL
+	}
+	sq.baseURL = endpoint
+}
+
+// SetTimeout sets the timeout of a single request, including reading the response body.
+// Retries with other keys get their own timeout. Zero disables the timeout.
+func (sq *SteamQuery) SetTimeout(timeout time.Duration) {
+	sq.timeout = timeout
+}
+
+// SetLogger sets the logger for diagnostic messages, nothing is logged by default.
+func (sq *SteamQuery) SetLogger(logger *slog.Logger) {
+	sq.logger = logger
+}
+
+// SetObserver sets the observer called after every HTTP request, e.g. to export metrics.
+func (sq *SteamQuery) SetObserver(observer webapi.Observer) {
+	sq.observer = observer
+}
+
 // SetLimit sets the maximum number of servers to retrieve in a single API request.
 // This overrides the default limit defined by DefaultLimit.
 func (sq *SteamQuery) SetLimit(limit int) {
 	sq.limit = limit
 }
 
+// Limit returns the maximum number of servers retrieved in a single API request.
+func (sq *SteamQuery) Limit() int {
+	return sq.limit
+}
+
 // Get performs a request to the Steam API with the provided filter and returns a list of servers.
 // It constructs the filter string, sends the HTTP GET request, and decodes the JSON response.
+// With the key provider of several keys it retries with the next key on 403 and 429 responses.
 // Returns an error if the request fails, the response status is not OK, or the response cannot be decoded.
 func (sq *SteamQuery) Get(filter *Filter) (Servers, error) {
+	return sq.GetContext(context.Background(), filter)
+}
+
+// GetContext is like Get but with the context to cancel the request.
+func (sq *SteamQuery) GetContext(ctx context.Context, filter *Filter) (Servers, error) {
 	filterString, err := filter.String()
 	if err != nil {
 		return nil, err
 	}
 
+	keys := sq.keys
+	if keys == nil {
+		keys = webapi.StaticKey(sq.key)
+	}
+
+	for retries := 0; retries < webapi.Attempts(keys); retries++ {
+		key, kerr := keys.Key()
+		if kerr != nil {
+			if err != nil {
+				return nil, err
+			}
+			return nil, kerr
+		}
+
+		var servers Servers
+		var status int
+		servers, status, err = sq.request(ctx, key, filterString, retries)
+		keys.Report(key, status)
+		if !webapi.Retryable(status) {
+			return servers, err
+		}
+	}
+
+	return nil, err
+}
+
+// request makes one request to the Steam API with the key and returns servers and the response status.
+func (sq *SteamQuery) request(ctx context.Context, key, filterString string, retries int) (servers Servers, status int, err error) {
+	info := webapi.RequestInfo{Endpoint: sq.baseURL, ChunkSize: sq.limit, Retries: retries}
+	start := time.Now()
+	defer func() {
+		info.Latency = time.Since(start)
+		info.Err = err
+		webapi.Observe(sq.observer, info)
+		webapi.Logger(sq.logger).Debug("steam api request",
+			"endpoint", info.Endpoint, "filter", filterString, "status", info.Status, "latency", info.Latency,
+			"bytes", info.Bytes, "retries", info.Retries, "servers", len(servers), "error", err,
+		)
+	}()
+
 	params := url.Values{}
-	params.Set("key", sq.key)
+	params.Set("key", key)
 	params.Set("filter", filterString)
 	params.Set("format", "json")
 	params.Set("limit", fmt.Sprintf("%d", sq.limit))
 
-	resp, err := sq.client.Get(baseURL + "?" + params.Encode())
+	if sq.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sq.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sq.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, 0, webapi.RedactError(err)
+	}
+
+	resp, err := sq.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, 0, fmt.Errorf("failed to execute request: %w", webapi.RedactError(err))
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Error close response body: %v", err)
+			webapi.Logger(sq.logger).Warn("failed to close response body", "error", err)
 		}
 	}()
 
+	info.Status = resp.StatusCode
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+		return nil, resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status)
 	}
 
 	var result struct {
@@ -72,9 +186,11 @@ func (sq *SteamQuery) Get(filter *Filter) (Servers, error) {
 		} `json:"response"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	body := &webapi.CountingReader{R: resp.Body}
+	defer func() { info.Bytes = body.N }()
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.Response.Servers, nil
+	return result.Response.Servers, resp.StatusCode, nil
 }
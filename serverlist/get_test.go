@@ -0,0 +1,42 @@
+package serverlist_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/steamtest"
+)
+
+func TestServerListContext(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+	srv.AddServers(
+		serverlist.Server{Addr: "10.0.0.1:27016", Appid: 221100},
+		serverlist.Server{Addr: "10.0.0.1:27017", Appid: 221100},
+		serverlist.Server{Addr: "10.0.0.2:27015", Appid: 730},
+	)
+
+	query := serverlist.New(steamtest.Key)
+	query.SetBaseURL(srv.URL + steamtest.PathGetServerList)
+
+	if servers, err := query.Get(&serverlist.Filter{}); err != nil || len(servers) != 3 {
+		t.Fatalf("Get() with base URL = %d servers, %v", len(servers), err)
+	}
+
+	srv.SetLatency(200 * time.Millisecond)
+	query.SetTimeout(20 * time.Millisecond)
+	if _, err := query.Get(&serverlist.Filter{}); err == nil || !strings.Contains(err.Error(), "deadline") {
+		t.Errorf("Get() with timeout returned %v, expected deadline error", err)
+	}
+
+	query.SetTimeout(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := query.GetContext(ctx, &serverlist.Filter{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetContext() with canceled context returned %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package serverlist
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/woozymasta/steam/utils/webapi"
+)
+
+// ErrTruncated is returned by GetAll with the partial result when some sub-queries still
+// reach the limit and can not be split any further.
+var ErrTruncated = errors.New("server list is truncated by limit")
+
+// maxShardValues limits the count of values of a single split, the rest is queried with nor of them
+const maxShardValues = 64
+
+// shardKeys are boolean filter keys used to split truncated queries into disjoint halves,
+// the first half adds key\1 and the second excludes it with nor. The flag reports the value
+// of the key for a server, it is nil if the value is not in the response.
+var shardKeys = []struct {
+	flag func(s *Server) bool
+	key  FilterKey
+}{
+	{func(s *Server) bool { return s.Dedicated }, KeyDedicated},
+	{func(s *Server) bool { return s.Secure }, KeySecure},
+	{func(s *Server) bool { return s.OS == OSLinux }, KeyLinux},
+	{func(s *Server) bool { return s.Players == 0 }, KeyNoPlayers},
+	{func(s *Server) bool { return s.Players < s.MaxPlayers }, KeyFull},
+	{nil, KeyPassword},
+}
+
+// stages is the count of split stages: regions, shardKeys, maps and server IPs
+var stages = len(shardKeys) + 3
+
+// GetAll is like Get but bypasses the limit of servers in a single response.
+// When the response is truncated, the query is split into disjoint sub-filters by regions,
+// by dedicated, secure, linux, noplayers, full and password flags, then by maps and server IPs.
+// The truncated response selects the splits: regions, maps and IPs are taken from it and
+// splits not dividing its servers are skipped, as they would repeat the query.
+// Results are merged and de-duplicated by SteamID (or Addr if SteamID is not set).
+// If some sub-query still reaches the limit, the merged servers are returned with ErrTruncated.
+func (sq *SteamQuery) GetAll(filter *Filter) (Servers, error) {
+	return sq.GetAllContext(context.Background(), filter)
+}
+
+// GetAllContext is like GetAll but with the context to cancel the requests.
+func (sq *SteamQuery) GetAllContext(ctx context.Context, filter *Filter) (Servers, error) {
+	if filter == nil {
+		filter = &Filter{}
+	}
+
+	s := &sharder{query: sq, seen: make(map[string]struct{})}
+	if err := s.get(ctx, filter, 0); err != nil {
+		return s.servers, err
+	}
+	if s.truncated {
+		return s.servers, ErrTruncated
+	}
+
+	return s.servers, nil
+}
+
+// sharder collects servers of recursively split queries
+type sharder struct {
+	query     *SteamQuery
+	seen      map[string]struct{}
+	servers   Servers
+	truncated bool
+}
+
+// get requests servers with the filter and splits it by the stages starting from stage if the response is truncated
+func (s *sharder) get(ctx context.Context, filter *Filter, stage int) error {
+	servers, err := s.query.GetContext(ctx, filter)
+	if err != nil {
+		return err
+	}
+	s.add(servers)
+
+	if s.query.limit <= 0 || len(servers) < s.query.limit {
+		return nil
+	}
+
+	for ; stage < stages; stage++ {
+		shards := split(stage, filter, servers)
+		if shards == nil {
+			continue
+		}
+
+		webapi.Logger(s.query.logger).Debug("server list is truncated, splitting query",
+			"servers", len(servers), "stage", stage, "shards", len(shards))
+
+		for _, shard := range shards {
+			if err := s.get(ctx, shard, stage+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	s.truncated = true
+	return nil
+}
+
+// split splits the filter by the stage: regions, shardKeys, maps and server IPs,
+// nil is returned if the stage can't split the filter
+func split(stage int, filter *Filter, servers Servers) []*Filter {
+	switch {
+	case stage == 0:
+		return splitValues(filter, servers, KeyRegion, func(s *Server) string { return strconv.Itoa(int(s.Region)) })
+	case stage <= len(shardKeys):
+		return splitFlag(filter, servers, shardKeys[stage-1].key, shardKeys[stage-1].flag)
+	case stage == len(shardKeys)+1:
+		return splitValues(filter, servers, KeyMap, func(s *Server) string {
+			if strings.Contains(s.Map, "\\") {
+				return ""
+			}
+			return s.Map
+		})
+	case stage == len(shardKeys)+2:
+		return splitValues(filter, servers, KeyGameAddr, func(s *Server) string {
+			if addr := s.AddrPort(); addr.Addr().Is4() {
+				return addr.Addr().String()
+			}
+			return ""
+		})
+	}
+
+	return nil
+}
+
+// splitFlag splits the filter into servers with key\1 and the rest,
+// nil is returned if all servers of the response have the same flag
+func splitFlag(filter *Filter, servers Servers, key FilterKey, flag func(s *Server) bool) []*Filter {
+	if filter.has(key) {
+		return nil
+	}
+	if flag != nil {
+		set := 0
+		for i := range servers {
+			if flag(&servers[i]) {
+				set++
+			}
+		}
+		if set == 0 || set == len(servers) {
+			return nil
+		}
+	}
+
+	with := filter.clone()
+	with.Add(key, "1")
+	without := filter.clone()
+	without.AddNor(key, "1")
+
+	return []*Filter{with, without}
+}
+
+// splitValues splits the filter by values of the key seen in the response and the rest of values,
+// nil is returned if the response has less than two values
+func splitValues(filter *Filter, servers Servers, key FilterKey, value func(s *Server) string) []*Filter {
+	if filter.has(key) {
+		return nil
+	}
+
+	var values []string
+	known := make(map[string]struct{})
+	for i := range servers {
+		v := value(&servers[i])
+		if _, ok := known[strings.ToLower(v)]; ok || v == "" {
+			continue
+		}
+		known[strings.ToLower(v)] = struct{}{}
+		values = append(values, v)
+	}
+	if len(values) < 2 {
+		return nil
+	}
+	if len(values) > maxShardValues {
+		values = values[:maxShardValues]
+	}
+
+	shards := make([]*Filter, 0, len(values)+1)
+	rest := filter.clone()
+	for _, v := range values {
+		with := filter.clone()
+		with.Add(key, v)
+		shards = append(shards, with)
+		rest.AddNor(key, v)
+	}
+
+	return append(shards, rest)
+}
+
+// add appends servers not seen before
+func (s *sharder) add(servers Servers) {
+	for _, srv := range servers {
+		id := serverKey(&srv)
+		if _, ok := s.seen[id]; ok {
+			continue
+		}
+		s.seen[id] = struct{}{}
+		s.servers = append(s.servers, srv)
+	}
+}
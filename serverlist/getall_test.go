@@ -0,0 +1,90 @@
+package serverlist_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/steamtest"
+)
+
+func TestServerListGetAll(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+
+	for i := 0; i < 24; i++ {
+		s := serverlist.Server{
+			Addr: fmt.Sprintf("10.0.1.%d:27016", i), SteamID: uint64(90000000000000000 + i), Appid: 730,
+			Map: fmt.Sprintf("map%d", i%3), Dedicated: i%2 == 0, Secure: i/2%2 == 0, OS: "w", MaxPlayers: 10,
+		}
+		if i/4%2 == 0 {
+			s.OS = "l"
+		}
+		if i/8%2 == 0 {
+			s.Players = 5
+		}
+		srv.AddServers(s)
+	}
+
+	query := serverlist.New(steamtest.Key)
+	query.SetClient(srv.Client())
+	query.SetLimit(3)
+
+	filter := &serverlist.Filter{}
+	filter.Add(serverlist.KeyAppID, "730")
+	servers, err := query.GetAll(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 24 {
+		t.Errorf("GetAll() returned %d servers, expected 24", len(servers))
+	}
+
+	seen := make(map[uint64]bool)
+	for _, s := range servers {
+		if seen[s.SteamID] {
+			t.Errorf("duplicate server %s", s.Addr)
+		}
+		seen[s.SteamID] = true
+	}
+
+	filters := make(map[string]bool)
+	for _, r := range srv.Requests() {
+		f := r.Query.Get("filter")
+		if filters[f] {
+			t.Errorf("filter %q is requested twice", f)
+		}
+		filters[f] = true
+	}
+
+	// servers of a truncated response in different regions are split by regions first
+	srv.Reset()
+	regions := &serverlist.Filter{}
+	regions.Add(serverlist.KeyAppID, "221100")
+	srv.AddServers(
+		serverlist.Server{Addr: "10.0.4.1:27016", SteamID: 1, Appid: 221100, Region: serverlist.RegionEurope},
+		serverlist.Server{Addr: "10.0.4.2:27016", SteamID: 2, Appid: 221100, Region: serverlist.RegionEurope},
+		serverlist.Server{Addr: "10.0.4.3:27016", SteamID: 3, Appid: 221100, Region: serverlist.RegionAsia},
+		serverlist.Server{Addr: "10.0.4.4:27016", SteamID: 4, Appid: 221100, Region: serverlist.RegionUSWest},
+	)
+	if servers, err = query.GetAll(regions); err != nil || len(servers) != 4 {
+		t.Fatalf("GetAll() by regions = %d servers, %v", len(servers), err)
+	}
+	if reqs := srv.Requests(); len(reqs) != 4 || !strings.Contains(reqs[1].Query.Get("filter"), `\region\`) {
+		t.Errorf("unexpected requests %+v", reqs)
+	}
+
+	// identical servers on the same IP can not be split
+	srv = steamtest.New()
+	defer srv.Close()
+	query.SetClient(srv.Client())
+	for i := 0; i < 5; i++ {
+		srv.AddServers(serverlist.Server{Addr: fmt.Sprintf("10.0.2.1:%d", 27016+i), Appid: 730, Map: "de_dust2"})
+	}
+	servers, err = query.GetAll(filter)
+	if !errors.Is(err, serverlist.ErrTruncated) || len(servers) != 3 {
+		t.Errorf("GetAll() = %d servers, %v, expected 3 and ErrTruncated", len(servers), err)
+	}
+}
@@ -0,0 +1,220 @@
+package serverlist
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/woozymasta/steam/utils/webapi"
+)
+
+const (
+	// DefaultMasterAddr is the address of the Steam master server for the UDP query protocol.
+	DefaultMasterAddr = "hl2master.steampowered.com:27011"
+
+	// DefaultMasterTimeout defines the default timeout of waiting for a single reply of the master server.
+	DefaultMasterTimeout = 5 * time.Second
+
+	// masterRequest is the header of the server list request
+	masterRequest = 0x31
+
+	// masterSeed is the first and the last address of the server list
+	masterSeed = "0.0.0.0:0"
+
+	// masterEntrySize is the size of IPv4 address and port in the reply
+	masterEntrySize = 6
+)
+
+// masterReplyHeader starts every reply of the master server
+var masterReplyHeader = []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x66, 0x0A}
+
+// ErrMasterReply is returned when the reply of the master server can not be decoded.
+var ErrMasterReply = errors.New("malformed master server reply")
+
+// Lister retrieves servers matching the filter, it is implemented by
+// SteamQuery (Steam Web API) and MasterQuery (UDP master server).
+type Lister interface {
+	Get(filter *Filter) (Servers, error)
+	GetContext(ctx context.Context, filter *Filter) (Servers, error)
+}
+
+var (
+	_ Lister = (*SteamQuery)(nil)
+	_ Lister = (*MasterQuery)(nil)
+)
+
+// MasterQuery retrieves servers from the master server with the deprecated UDP
+// Master Server Query Protocol. It does not need an API key, but the reply has
+// only addresses, so only Server.Addr is set (e.g. for a2s queries).
+type MasterQuery struct {
+	logger  *slog.Logger
+	addr    string
+	timeout time.Duration
+	limit   int
+	region  Region
+}
+
+// NewMaster creates a new instance of MasterQuery for DefaultMasterAddr and all regions.
+func NewMaster() *MasterQuery {
+	return &MasterQuery{
+		addr:    DefaultMasterAddr,
+		timeout: DefaultMasterTimeout,
+		limit:   DefaultLimit,
+		region:  RegionWorld,
+	}
+}
+
+// SetAddr sets the "host:port" address of the master server, e.g. for a local stand-in.
+// An empty address resets DefaultMasterAddr.
+func (mq *MasterQuery) SetAddr(addr string) {
+	if addr == "" {
+		addr = DefaultMasterAddr
+	}
+	mq.addr = addr
+}
+
+// SetRegion sets the region of servers, RegionWorld (default) requests all regions.
+func (mq *MasterQuery) SetRegion(region Region) {
+	mq.region = region
+}
+
+// SetTimeout sets the timeout of waiting for a single reply. Zero disables the timeout.
+func (mq *MasterQuery) SetTimeout(timeout time.Duration) {
+	mq.timeout = timeout
+}
+
+// SetLimit sets the maximum number of servers to retrieve, zero or less disables the limit.
+func (mq *MasterQuery) SetLimit(limit int) {
+	mq.limit = limit
+}
+
+// Limit returns the maximum number of servers to retrieve, zero or less if there is no limit.
+func (mq *MasterQuery) Limit() int {
+	return mq.limit
+}
+
+// SetLogger sets the logger for diagnostic messages, nothing is logged by default.
+func (mq *MasterQuery) SetLogger(logger *slog.Logger) {
+	mq.logger = logger
+}
+
+// Get requests servers matching the filter from the master server page by page,
+// every next request is seeded with the last address of the previous reply.
+func (mq *MasterQuery) Get(filter *Filter) (Servers, error) {
+	return mq.GetContext(context.Background(), filter)
+}
+
+// GetContext is like Get but with the context to cancel the requests.
+func (mq *MasterQuery) GetContext(ctx context.Context, filter *Filter) (Servers, error) {
+	if filter == nil {
+		filter = &Filter{}
+	}
+	filterString, err := filter.String()
+	if err != nil {
+		return nil, err
+	}
+	if filterString != "" {
+		filterString = "\\" + filterString
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", mq.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect master server %s: %w", mq.addr, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	var servers Servers
+	seen := make(map[string]struct{})
+	seed := masterSeed
+	for pages := 1; ; pages++ {
+		addrs, err := mq.page(conn, seed, filterString)
+		if err != nil {
+			if ctx.Err() != nil {
+				return servers, ctx.Err()
+			}
+			return servers, err
+		}
+
+		done := len(addrs) == 0
+		for _, addr := range addrs {
+			if addr == masterSeed {
+				done = true
+				break
+			}
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+			seen[addr] = struct{}{}
+			servers = append(servers, Server{Addr: addr})
+			if mq.limit > 0 && len(servers) >= mq.limit {
+				done = true
+				break
+			}
+		}
+
+		webapi.Logger(mq.logger).Debug("master server reply",
+			"addr", mq.addr, "filter", filterString, "seed", seed, "page", pages, "servers", len(servers))
+
+		if done || addrs[len(addrs)-1] == seed {
+			return servers, nil
+		}
+		seed = addrs[len(addrs)-1]
+	}
+}
+
+// page sends the request seeded with the address and returns addresses of the reply
+func (mq *MasterQuery) page(conn net.Conn, seed, filterString string) ([]string, error) {
+	request := make([]byte, 0, 2+len(seed)+len(filterString)+2)
+	request = append(request, masterRequest, byte(mq.region))
+	request = append(append(request, seed...), 0)
+	request = append(append(request, filterString...), 0)
+
+	if mq.timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(mq.timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := conn.Write(request); err != nil {
+		return nil, fmt.Errorf("failed to send request to master server: %w", err)
+	}
+
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master server reply: %w", err)
+	}
+
+	return parseMasterReply(buf[:n])
+}
+
+// parseMasterReply decodes the reply: header and IPv4 addresses with big-endian ports
+func parseMasterReply(reply []byte) ([]string, error) {
+	if !bytes.HasPrefix(reply, masterReplyHeader) {
+		return nil, fmt.Errorf("%w: unexpected header %x", ErrMasterReply, reply[:min(len(reply), len(masterReplyHeader))])
+	}
+
+	data := reply[len(masterReplyHeader):]
+	if len(data)%masterEntrySize != 0 {
+		return nil, fmt.Errorf("%w: %d bytes of addresses", ErrMasterReply, len(data))
+	}
+
+	addrs := make([]string, 0, len(data)/masterEntrySize)
+	for i := 0; i < len(data); i += masterEntrySize {
+		ip := netip.AddrFrom4([4]byte(data[i : i+4]))
+		port := binary.BigEndian.Uint16(data[i+4:])
+		addrs = append(addrs, netip.AddrPortFrom(ip, port).String())
+	}
+
+	return addrs, nil
+}
@@ -0,0 +1,60 @@
+package serverlist_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/steamtest"
+)
+
+func TestMaster(t *testing.T) {
+	master := steamtest.NewMaster()
+	defer func() { _ = master.Close() }()
+	master.SetPageSize(2)
+
+	master.AddServers(
+		serverlist.Server{Addr: "10.0.0.1:27016", Appid: 221100, Region: serverlist.RegionEurope},
+		serverlist.Server{Addr: "10.0.0.1:27017", Appid: 221100},
+		serverlist.Server{Addr: "10.0.0.2:27015", Appid: 730},
+	)
+	for i := 0; i < 5; i++ {
+		master.AddServers(serverlist.Server{Addr: fmt.Sprintf("10.0.3.%d:27016", i), Appid: 730, Region: 3})
+	}
+
+	query := serverlist.NewMaster()
+	query.SetAddr(master.Addr())
+	query.SetTimeout(time.Second)
+
+	var lister serverlist.Lister = query
+	servers, err := lister.Get(&serverlist.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 8 || servers[0].Addr != "10.0.0.1:27016" || servers[7].Addr != "10.0.3.4:27016" {
+		t.Errorf("Get() = %v", servers)
+	}
+	if requests := master.Requests(); len(requests) != 4 || requests[0].Seed != "0.0.0.0:0" ||
+		requests[1].Seed != "10.0.0.1:27017" || requests[0].Region != serverlist.RegionWorld {
+		t.Errorf("unexpected requests %+v", requests)
+	}
+
+	filter := (&serverlist.Filter{}).AppID(730)
+	query.SetRegion(serverlist.RegionEurope)
+	query.SetLimit(3)
+	if servers, err = query.Get(filter); err != nil || len(servers) != 3 || servers[0].Addr != "10.0.3.0:27016" {
+		t.Errorf("Get() with filter, region and limit = %v, %v", servers, err)
+	}
+	if r := master.Requests(); r[len(r)-1].Filter != `\appid\730` {
+		t.Errorf("filter of request = %q", r[len(r)-1].Filter)
+	}
+
+	silent := steamtest.NewMaster()
+	_ = silent.Close()
+	query.SetAddr(silent.Addr())
+	query.SetTimeout(50 * time.Millisecond)
+	if _, err := query.Get(filter); err == nil {
+		t.Error("Get() of closed master server returned no error")
+	}
+}
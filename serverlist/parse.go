@@ -0,0 +1,154 @@
+package serverlist
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError describes malformed filter string.
+type ParseError struct {
+	Token  string // Token at the offset
+	Reason string // Description of the problem
+	Offset int    // Byte offset of the token in the filter string
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid filter at offset %d near %q: %s", e.Offset, e.Token, e.Reason)
+}
+
+// token is a part of the filter string between backslashes
+type token struct {
+	value  string
+	offset int
+}
+
+// ParseFilter parses the filter string, the inverse of Filter.String.
+// The leading backslash is optional, groups may be nested, a nested group counts
+// as a single condition of the parent group. Top level "and" groups of conditions
+// are merged with standard conditions and "nor" groups of conditions are merged into one,
+// since the result is the same.
+// Returns *ParseError with the offset of the malformed token.
+func ParseFilter(s string) (*Filter, error) {
+	p := &parser{tokens: tokenize(s)}
+	f := &Filter{}
+
+	for p.pos < len(p.tokens) {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+
+		switch e := e.(type) {
+		case Condition:
+			f.conditions = append(f.conditions, string(e.Key)+"\\"+e.Value)
+		case *Group:
+			conditions, flat := flatConditions(e)
+			switch {
+			case flat && e.Op == OpAnd:
+				f.conditions = append(f.conditions, conditions...)
+			case flat && e.Op == OpNor:
+				f.norConditions = append(f.norConditions, conditions...)
+			case flat && e.Op == OpNand && f.nandConditions == nil:
+				f.nandConditions = conditions
+			default:
+				f.groups = append(f.groups, e)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// Canonical returns the filter string with sorted conditions and groups at every level,
+// equal filters have the same canonical form regardless of the order of conditions.
+func (f *Filter) Canonical() (string, error) {
+	return f.canonical().(*Filter).String()
+}
+
+// parser reads expressions from tokens
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// expr reads a condition or a group with its items
+func (p *parser) expr() (Expr, error) {
+	key := p.tokens[p.pos]
+	if key.value == "" {
+		return nil, &ParseError{Offset: key.offset, Token: key.value, Reason: "empty key"}
+	}
+	if p.pos+1 >= len(p.tokens) {
+		return nil, &ParseError{Offset: key.offset, Token: key.value, Reason: "missing value"}
+	}
+	value := p.tokens[p.pos+1]
+	p.pos += 2
+
+	op := Operator(key.value)
+	switch op {
+	case OpAnd, OpOr, OpNor, OpNand:
+	default:
+		return Cond(FilterKey(key.value), value.value), nil
+	}
+
+	count, err := strconv.Atoi(value.value)
+	if err != nil || count < 1 {
+		return nil, &ParseError{Offset: value.offset, Token: value.value, Reason: "group count must be a positive number"}
+	}
+
+	group := &Group{Op: op, Items: make([]Expr, 0, count)}
+	for i := 0; i < count; i++ {
+		if p.pos >= len(p.tokens) {
+			last := p.tokens[len(p.tokens)-1]
+			return nil, &ParseError{Offset: last.offset, Token: last.value, Reason: fmt.Sprintf("%s group expects %d conditions", op, count)}
+		}
+
+		item, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		group.Items = append(group.Items, item)
+	}
+
+	return group, nil
+}
+
+// flatConditions returns conditions of the group if it has no nested groups
+func flatConditions(g *Group) ([]string, bool) {
+	conditions := make([]string, 0, len(g.Items))
+	for _, item := range g.Items {
+		c, ok := item.(Condition)
+		if !ok {
+			return nil, false
+		}
+		conditions = append(conditions, string(c.Key)+"\\"+c.Value)
+	}
+
+	return conditions, true
+}
+
+// tokenize splits the filter string by backslashes, skipping the leading one and the trailing one
+// if it does not end a complete pair, e.g. the map key of `appid\730\map\` has an empty value
+func tokenize(s string) []token {
+	offset := 0
+	if strings.HasPrefix(s, "\\") {
+		offset = 1
+	}
+
+	parts := strings.Split(s[offset:], "\\")
+	if len(parts)%2 == 1 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+
+	tokens := make([]token, len(parts))
+	for i, part := range parts {
+		tokens[i] = token{value: part, offset: offset}
+		offset += len(part) + 1
+	}
+
+	return tokens
+}
@@ -0,0 +1,104 @@
+package serverlist
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	json "github.com/json-iterator/go"
+)
+
+// Region is a region code of the master server.
+// It is encoded to JSON as the region name and decoded from the name or the code.
+type Region int
+
+const (
+	RegionUSEast       Region = 0   // US East coast
+	RegionUSWest       Region = 1   // US West coast
+	RegionSouthAmerica Region = 2   // South America
+	RegionEurope       Region = 3   // Europe
+	RegionAsia         Region = 4   // Asia
+	RegionAustralia    Region = 5   // Australia
+	RegionMiddleEast   Region = 6   // Middle East
+	RegionAfrica       Region = 7   // Africa
+	RegionWorld        Region = 255 // Rest of the world
+)
+
+// regionNames maps known regions to names
+var regionNames = map[Region]string{
+	RegionUSEast:       "US East",
+	RegionUSWest:       "US West",
+	RegionSouthAmerica: "South America",
+	RegionEurope:       "Europe",
+	RegionAsia:         "Asia",
+	RegionAustralia:    "Australia",
+	RegionMiddleEast:   "Middle East",
+	RegionAfrica:       "Africa",
+	RegionWorld:        "World",
+}
+
+// RegionUnknown is the name of undefined region codes returned by Region.String.
+const RegionUnknown = "Unknown"
+
+// ParseRegion returns the region by the name (case-insensitive) or the code,
+// codes of undefined regions are rejected.
+func ParseRegion(s string) (Region, error) {
+	for r, name := range regionNames {
+		if strings.EqualFold(name, s) {
+			return r, nil
+		}
+	}
+
+	code, err := strconv.Atoi(s)
+	if err != nil || !Region(code).valid() {
+		return 0, fmt.Errorf("unknown region %q", s)
+	}
+
+	return Region(code), nil
+}
+
+// String returns the region name, or RegionUnknown for undefined region codes.
+func (r Region) String() string {
+	if name, ok := regionNames[r]; ok {
+		return name
+	}
+
+	return RegionUnknown
+}
+
+// MarshalJSON implements the json.Marshaler interface, known regions are encoded by name.
+func (r Region) MarshalJSON() ([]byte, error) {
+	if name, ok := regionNames[r]; ok {
+		return json.Marshal(name)
+	}
+
+	return []byte(strconv.Itoa(int(r))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, it accepts the region code or name.
+func (r *Region) UnmarshalJSON(data []byte) error {
+	var code int
+	if err := json.Unmarshal(data, &code); err == nil {
+		*r = Region(code)
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("region must be a number or a name: %w", err)
+	}
+
+	region, err := ParseRegion(name)
+	if err != nil {
+		return err
+	}
+
+	*r = region
+	return nil
+}
+
+// valid reports whether the region code is known
+func (r Region) valid() bool {
+	_, ok := regionNames[r]
+	return ok
+}
@@ -1,6 +1,7 @@
 package serverlist
 
 import (
+	"net/netip"
 	"strings"
 
 	json "github.com/json-iterator/go"
@@ -10,8 +11,21 @@ import (
 type GameType []string
 
 // UnmarshalJSON implements the json.Unmarshaler interface for the GameType type.
-// It splits a comma-separated string into a slice of trimmed strings.
+// It splits a comma-separated string into a slice of trimmed strings,
+// an array of strings is accepted too to decode the encoded Server back.
 func (gt *GameType) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '[' {
+		var tags []string
+		if err := json.Unmarshal(data, &tags); err != nil {
+			return err
+		}
+		*gt = tags
+		return nil
+	}
+
 	var s string
 	if err := json.Unmarshal(data, &s); err != nil {
 		return err
@@ -31,23 +45,71 @@ type Servers []Server
 
 // Server represents the structure of a game server as returned by the Steam Master Server Query Protocol.
 type Server struct {
-	Addr       string   `json:"addr"`             // Server address in the format IP:Port
-	GameDir    string   `json:"gamedir"`          // Game directory (e.g., "cstrike" for Counter-Strike)
-	Map        string   `json:"map"`              // Current map on the server
-	Name       string   `json:"name"`             // Server name
-	Product    string   `json:"product"`          // Product or game associated with the server
-	Version    string   `json:"version"`          // Game version running on the server
-	OS         string   `json:"os"`               // Server operating system ("l" for Linux, "w" for Windows)
-	GameType   GameType `json:"gametype"`         // Game type or server tags (from sv_tags or "battleye,lqs0,etm2.300000")
-	SteamID    uint64   `json:"steamid,string"`   // Server SteamID
-	Appid      uint64   `json:"appid"`            // Game ID (e.g., 221100 for DayZ)
-	Bots       uint16   `json:"bots,omitempty"`   // Number of bots on the server
-	GamePort   uint16   `json:"gameport"`         // Game port (for client connections)
-	MaxPlayers uint16   `json:"max_players"`      // Maximum number of players on the server
-	Players    uint16   `json:"players"`          // Current number of players on the server
-	Region     int      `json:"region,omitempty"` // Server region code
-	Dedicated  bool     `json:"dedicated"`        // Indicates if the server is dedicated
-	Secure     bool     `json:"secure"`           // Indicates if protection is enabled (e.g., VAC or BattlEye)
+	Addr       string   `json:"addr"`           // Server address in the format IP:Port
+	GameDir    string   `json:"gamedir"`        // Game directory (e.g., "cstrike" for Counter-Strike)
+	Map        string   `json:"map"`            // Current map on the server
+	Name       string   `json:"name"`           // Server name
+	Product    string   `json:"product"`        // Product or game associated with the server
+	Version    string   `json:"version"`        // Game version running on the server
+	OS         OS       `json:"os"`             // Server operating system ("l" for Linux, "w" for Windows)
+	GameType   GameType `json:"gametype"`       // Game type or server tags (from sv_tags or "battleye,lqs0,etm2.300000")
+	SteamID    uint64   `json:"steamid,string"` // Server SteamID
+	Appid      uint64   `json:"appid"`          // Game ID (e.g., 221100 for DayZ)
+	Bots       uint16   `json:"bots,omitempty"` // Number of bots on the server
+	GamePort   uint16   `json:"gameport"`       // Game port (for client connections)
+	MaxPlayers uint16   `json:"max_players"`    // Maximum number of players on the server
+	Players    uint16   `json:"players"`        // Current number of players on the server
+	Region     Region   `json:"region"`         // Server region code
+	Dedicated  bool     `json:"dedicated"`      // Indicates if the server is dedicated
+	Secure     bool     `json:"secure"`         // Indicates if protection is enabled (e.g., VAC or BattlEye)
+}
+
+// OS is the operating system of the server.
+type OS string
+
+const (
+	OSLinux   OS = "l" // Linux
+	OSWindows OS = "w" // Windows
+	OSMac     OS = "m" // macOS
+)
+
+// String returns the operating system name.
+func (o OS) String() string {
+	switch o {
+	case OSLinux:
+		return "Linux"
+	case OSWindows:
+		return "Windows"
+	case OSMac, "o":
+		return "macOS"
+	}
+
+	return string(o)
+}
+
+// AddrPort returns the parsed server address with the query port, or zero value if Addr is invalid.
+func (s *Server) AddrPort() netip.AddrPort {
+	addr, err := netip.ParseAddrPort(s.Addr)
+	if err != nil {
+		return netip.AddrPort{}
+	}
+
+	return netip.AddrPortFrom(addr.Addr().Unmap(), addr.Port())
+}
+
+// QueryPort returns the port of the server address used for A2S queries.
+func (s *Server) QueryPort() uint16 {
+	return s.AddrPort().Port()
+}
+
+// GameAddr returns the server IP with the game port used for client connections.
+func (s *Server) GameAddr() netip.AddrPort {
+	addr := s.AddrPort()
+	if !addr.IsValid() {
+		return netip.AddrPort{}
+	}
+
+	return netip.AddrPortFrom(addr.Addr(), s.GamePort)
 }
 
 // GetVersionMap populates the version map from the server list
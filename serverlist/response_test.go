@@ -0,0 +1,77 @@
+package serverlist_test
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	json "github.com/json-iterator/go"
+	"github.com/woozymasta/steam/serverlist"
+)
+
+func TestServerAddr(t *testing.T) {
+	s := serverlist.Server{Addr: "10.0.0.1:27016", GamePort: 2302}
+	if s.AddrPort() != netip.MustParseAddrPort("10.0.0.1:27016") || s.QueryPort() != 27016 {
+		t.Errorf("AddrPort() = %s, QueryPort() = %d", s.AddrPort(), s.QueryPort())
+	}
+	if s.GameAddr() != netip.MustParseAddrPort("10.0.0.1:2302") {
+		t.Errorf("GameAddr() = %s", s.GameAddr())
+	}
+
+	bad := serverlist.Server{Addr: "bad", GamePort: 2302}
+	if bad.AddrPort().IsValid() || bad.GameAddr().IsValid() || bad.QueryPort() != 0 {
+		t.Errorf("invalid address parsed as %s", bad.AddrPort())
+	}
+}
+
+func TestOSRegion(t *testing.T) {
+	if serverlist.OSLinux.String() != "Linux" || serverlist.OS("x").String() != "x" {
+		t.Errorf("OS.String() = %s", serverlist.OSLinux)
+	}
+	if serverlist.RegionEurope.String() != "Europe" || serverlist.Region(42).String() != "Unknown" {
+		t.Errorf("Region.String() = %s", serverlist.RegionEurope)
+	}
+
+	for _, s := range []string{"middle east", "6"} {
+		if r, err := serverlist.ParseRegion(s); err != nil || r != serverlist.RegionMiddleEast {
+			t.Errorf("ParseRegion(%q) = %v, %v", s, r, err)
+		}
+	}
+	for _, s := range []string{"Mars", "42", "-1", "Unknown"} {
+		if _, err := serverlist.ParseRegion(s); err == nil {
+			t.Errorf("ParseRegion(%q) of undefined region must fail", s)
+		}
+	}
+}
+
+func TestServerJSON(t *testing.T) {
+	var servers serverlist.Servers
+	data := `[{"addr":"10.0.0.1:27016","os":"l","region":0,"gametype":"battleye,lqs0","steamid":"90200000000000001"},` +
+		`{"addr":"10.0.0.2:27016","os":"w","region":255,"gametype":""},{"addr":"10.0.0.3:27016","region":42}]`
+	if err := json.Unmarshal([]byte(data), &servers); err != nil {
+		t.Fatal(err)
+	}
+	if servers[0].OS != serverlist.OSLinux || servers[0].Region != serverlist.RegionUSEast || servers[1].Region != serverlist.RegionWorld {
+		t.Errorf("unexpected servers %+v", servers)
+	}
+
+	encoded, err := json.Marshal(servers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded serverlist.Servers
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("decode %s: %v", encoded, err)
+	}
+	if !reflect.DeepEqual(servers, decoded) {
+		t.Errorf("round trip:\n%+v\n%+v", servers, decoded)
+	}
+
+	var s struct {
+		Region serverlist.Region `json:"region"`
+	}
+	if err := json.Unmarshal([]byte(`{"region":"US West"}`), &s); err != nil || s.Region != serverlist.RegionUSWest {
+		t.Errorf("region name decoded as %v: %v", s.Region, err)
+	}
+}
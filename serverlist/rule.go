@@ -0,0 +1,409 @@
+package serverlist
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/woozymasta/steam/utils/latest"
+)
+
+/*
+ParseRule parses the text expression into the predicate, e.g. for rules in configuration files:
+
+	players >= 10 and version >= "1.26" and not (name ~ "(?i)test" or gametype has external)
+
+Comparisons are combined with "and" ("&&"), "or" ("||"), "not" ("!") and parentheses.
+Values are numbers, words or double quoted strings. Fields and operators:
+
+  - players, max_players, bots, free (slots), ratio (players to max players 0..1),
+    appid, region (code or name, e.g. region = Europe), gameport: = != < <= > >=
+  - version: = != < <= > >= compared with latest.CompareVersions
+  - name, map, gamedir, product, os, addr: = != (case-insensitive), ~ !~ (regular expression)
+  - dedicated, secure: = != with true or false
+  - gametype: has (case-insensitive tag)
+
+Returns *ParseError with the offset of the malformed token.
+*/
+func ParseRule(rule string) (Predicate, error) {
+	tokens, err := lexRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ruleParser{tokens: tokens, end: len(rule)}
+	pred, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.fail(p.tokens[p.pos], "unexpected token")
+	}
+
+	return pred, nil
+}
+
+// ruleFieldKind is the type of a server field in rules
+type ruleFieldKind int
+
+const (
+	ruleNumber ruleFieldKind = iota
+	ruleString
+	ruleVersion
+	ruleBool
+	ruleTags
+)
+
+// ruleField describes a server field available in rules
+type ruleField struct {
+	number func(s *Server) float64
+	text   func(s *Server) string
+	flag   func(s *Server) bool
+	name   func(value string) (float64, error) // parses named values of a number field
+	kind   ruleFieldKind
+}
+
+// ruleFields are server fields available in rules
+var ruleFields = map[string]ruleField{
+	"players":     {kind: ruleNumber, number: func(s *Server) float64 { return float64(s.Players) }},
+	"max_players": {kind: ruleNumber, number: func(s *Server) float64 { return float64(s.MaxPlayers) }},
+	"bots":        {kind: ruleNumber, number: func(s *Server) float64 { return float64(s.Bots) }},
+	"free":        {kind: ruleNumber, number: func(s *Server) float64 { return float64(s.MaxPlayers) - float64(s.Players) }},
+	"ratio":       {kind: ruleNumber, number: playerRatio},
+	"appid":       {kind: ruleNumber, number: func(s *Server) float64 { return float64(s.Appid) }},
+	"region":      {kind: ruleNumber, number: func(s *Server) float64 { return float64(s.Region) }, name: regionNumber},
+	"gameport":    {kind: ruleNumber, number: func(s *Server) float64 { return float64(s.GamePort) }},
+	"version":     {kind: ruleVersion, text: func(s *Server) string { return s.Version }},
+	"name":        {kind: ruleString, text: func(s *Server) string { return s.Name }},
+	"map":         {kind: ruleString, text: func(s *Server) string { return s.Map }},
+	"gamedir":     {kind: ruleString, text: func(s *Server) string { return s.GameDir }},
+	"product":     {kind: ruleString, text: func(s *Server) string { return s.Product }},
+	"os":          {kind: ruleString, text: func(s *Server) string { return string(s.OS) }},
+	"addr":        {kind: ruleString, text: func(s *Server) string { return s.Addr }},
+	"dedicated":   {kind: ruleBool, flag: func(s *Server) bool { return s.Dedicated }},
+	"secure":      {kind: ruleBool, flag: func(s *Server) bool { return s.Secure }},
+	"gametype":    {kind: ruleTags},
+}
+
+// ruleTokenKind is the kind of a rule token
+type ruleTokenKind int
+
+const (
+	ruleWord ruleTokenKind = iota
+	ruleQuoted
+	ruleOperator
+	ruleParen
+)
+
+// ruleToken is a lexical token of a rule
+type ruleToken struct {
+	value  string
+	offset int
+	kind   ruleTokenKind
+}
+
+// ruleParser is a recursive descent parser of rules
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+	end    int
+}
+
+// or parses: and { "or" and }
+func (p *ruleParser) or() (Predicate, error) {
+	pred, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	preds := []Predicate{pred}
+	for p.accept("or", "||") {
+		if pred, err = p.and(); err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+
+	if len(preds) == 1 {
+		return preds[0], nil
+	}
+	return Any(preds...), nil
+}
+
+// and parses: unary { "and" unary }
+func (p *ruleParser) and() (Predicate, error) {
+	pred, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	preds := []Predicate{pred}
+	for p.accept("and", "&&") {
+		if pred, err = p.unary(); err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+
+	if len(preds) == 1 {
+		return preds[0], nil
+	}
+	return All(preds...), nil
+}
+
+// unary parses: "not" unary | "(" or ")" | comparison
+func (p *ruleParser) unary() (Predicate, error) {
+	if p.accept("not", "!") {
+		pred, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(pred), nil
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.kind == ruleParen && t.value == "(" {
+		pred, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		closing, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if closing.kind != ruleParen || closing.value != ")" {
+			return nil, p.fail(closing, "expected )")
+		}
+		return pred, nil
+	}
+
+	return p.comparison(t)
+}
+
+// comparison parses: field operator value
+func (p *ruleParser) comparison(name ruleToken) (Predicate, error) {
+	field, ok := ruleFields[strings.ToLower(name.value)]
+	if name.kind != ruleWord || !ok {
+		return nil, p.fail(name, "unknown field")
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if op.kind != ruleOperator && !(op.kind == ruleWord && op.value == "has") {
+		return nil, p.fail(op, "expected operator")
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if value.kind != ruleWord && value.kind != ruleQuoted {
+		return nil, p.fail(value, "expected value")
+	}
+
+	switch field.kind {
+	case ruleNumber:
+		n, err := strconv.ParseFloat(value.value, 64)
+		if err != nil && field.name != nil {
+			if n, err = field.name(value.value); err != nil {
+				return nil, p.fail(value, err.Error())
+			}
+		}
+		if err != nil {
+			return nil, p.fail(value, "expected number")
+		}
+		cmp, ok := compareOp(op.value)
+		if !ok {
+			return nil, p.fail(op, "unsupported operator for number")
+		}
+		return func(s *Server) bool {
+			v := field.number(s)
+			return cmp(boolToInt(v > n) - boolToInt(v < n))
+		}, nil
+
+	case ruleVersion:
+		cmp, ok := compareOp(op.value)
+		if !ok {
+			return nil, p.fail(op, "unsupported operator for version")
+		}
+		return func(s *Server) bool {
+			return cmp(int(latest.CompareVersions(field.text(s), value.value)))
+		}, nil
+
+	case ruleString:
+		switch op.value {
+		case "=", "==":
+			return func(s *Server) bool { return strings.EqualFold(field.text(s), value.value) }, nil
+		case "!=":
+			return func(s *Server) bool { return !strings.EqualFold(field.text(s), value.value) }, nil
+		case "~", "!~":
+			re, err := regexp.Compile(value.value)
+			if err != nil {
+				return nil, p.fail(value, err.Error())
+			}
+			negate := op.value == "!~"
+			return func(s *Server) bool { return re.MatchString(field.text(s)) != negate }, nil
+		}
+		return nil, p.fail(op, "unsupported operator for string")
+
+	case ruleBool:
+		b, err := strconv.ParseBool(value.value)
+		if err != nil {
+			return nil, p.fail(value, "expected true or false")
+		}
+		switch op.value {
+		case "=", "==":
+			return func(s *Server) bool { return field.flag(s) == b }, nil
+		case "!=":
+			return func(s *Server) bool { return field.flag(s) != b }, nil
+		}
+		return nil, p.fail(op, "unsupported operator for bool")
+
+	default:
+		if op.value != "has" {
+			return nil, p.fail(op, "unsupported operator for gametype, use has")
+		}
+		return HasGameType(value.value), nil
+	}
+}
+
+// accept consumes the next token if it is one of the words
+func (p *ruleParser) accept(words ...string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+
+	t := p.tokens[p.pos]
+	if t.kind != ruleWord && t.kind != ruleOperator {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.value, w) {
+			p.pos++
+			return true
+		}
+	}
+
+	return false
+}
+
+// next returns the next token or error at the end of the rule
+func (p *ruleParser) next() (ruleToken, error) {
+	if p.pos >= len(p.tokens) {
+		return ruleToken{}, &ParseError{Offset: p.end, Reason: "unexpected end of rule"}
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+// fail returns the parse error at the token
+func (p *ruleParser) fail(t ruleToken, reason string) error {
+	return &ParseError{Offset: t.offset, Token: t.value, Reason: reason}
+}
+
+// lexRule splits the rule into tokens
+func lexRule(rule string) ([]ruleToken, error) {
+	var tokens []ruleToken
+
+	for i := 0; i < len(rule); {
+		c := rule[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(' || c == ')':
+			tokens = append(tokens, ruleToken{kind: ruleParen, value: string(c), offset: i})
+			i++
+
+		case c == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(rule) && rule[j] != '"'; j++ {
+				if rule[j] == '\\' && j+1 < len(rule) {
+					j++
+				}
+				sb.WriteByte(rule[j])
+			}
+			if j >= len(rule) {
+				return nil, &ParseError{Offset: i, Token: rule[i:], Reason: "unterminated string"}
+			}
+			tokens = append(tokens, ruleToken{kind: ruleQuoted, value: sb.String(), offset: i})
+			i = j + 1
+
+		case strings.IndexByte("=!<>~&|", c) >= 0:
+			// The longest operator is taken, so "&&!" is lexed as "&&" and "!"
+			op := rule[i : i+1]
+			if i+1 < len(rule) && isRuleOperator(rule[i:i+2]) {
+				op = rule[i : i+2]
+			}
+			if !isRuleOperator(op) {
+				return nil, &ParseError{Offset: i, Token: op, Reason: "unknown operator"}
+			}
+			tokens = append(tokens, ruleToken{kind: ruleOperator, value: op, offset: i})
+			i += len(op)
+
+		default:
+			j := i
+			for j < len(rule) && strings.IndexByte(" \t\n\r()\"=!<>~&|", rule[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleWord, value: rule[i:j], offset: i})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// isRuleOperator reports whether the string is an operator of rule expressions
+func isRuleOperator(op string) bool {
+	switch op {
+	case "=", "==", "!=", "<", "<=", ">", ">=", "~", "!~", "&&", "||", "!":
+		return true
+	}
+
+	return false
+}
+
+// compareOp returns the function checking the result of comparison -1, 0 or 1 for the operator
+func compareOp(op string) (func(c int) bool, bool) {
+	switch op {
+	case "=", "==":
+		return func(c int) bool { return c == 0 }, true
+	case "!=":
+		return func(c int) bool { return c != 0 }, true
+	case "<":
+		return func(c int) bool { return c < 0 }, true
+	case "<=":
+		return func(c int) bool { return c <= 0 }, true
+	case ">":
+		return func(c int) bool { return c > 0 }, true
+	case ">=":
+		return func(c int) bool { return c >= 0 }, true
+	}
+
+	return nil, false
+}
+
+// regionNumber returns the region code by the name for rules
+func regionNumber(name string) (float64, error) {
+	r, err := ParseRegion(name)
+	return float64(r), err
+}
+
+// boolToInt returns 1 for true and 0 for false
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
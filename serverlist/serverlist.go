@@ -57,10 +57,15 @@ To obtain an API key, visit: [Steam Dev API Key]
 */
 package serverlist
 
+import "time"
+
 const (
 	// DefaultLimit defines the default maximum number of servers to retrieve.
 	DefaultLimit = 10000
 
+	// DefaultTimeout defines the default timeout of a single request to the Steam API.
+	DefaultTimeout = 30 * time.Second
+
 	// baseURL is the endpoint for the Steam Game Servers API.
 	baseURL = "https://api.steampowered.com/IGameServersService/GetServerList/v1/"
 )
@@ -1,10 +1,12 @@
-package serverlist
+package serverlist_test
 
 import (
 	"fmt"
 	"os"
 	"testing"
 
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/steamtest"
 	"github.com/woozymasta/steam/utils/appid"
 )
 
@@ -12,11 +14,11 @@ import (
 func TestGetCS2Servers(t *testing.T) {
 	var id uint64 = appid.CounterStrike2.Uint64()
 
-	filter := &Filter{}
-	filter.Add(KeyAppID, fmt.Sprintf("%d", id))
-	filter.Add(KeyMap, "de_dust2")
-	filter.AddNor(KeyGameType, "insecure")
-	filter.AddNor(KeyGameType, "empty")
+	filter := &serverlist.Filter{}
+	filter.Add(serverlist.KeyAppID, fmt.Sprintf("%d", id))
+	filter.Add(serverlist.KeyMap, "de_dust2")
+	filter.AddNor(serverlist.KeyGameType, "insecure")
+	filter.AddNor(serverlist.KeyGameType, "empty")
 
 	if err := helperGetServers(id, filter, 50); err != nil {
 		t.Error(err)
@@ -27,9 +29,9 @@ func TestGetCS2Servers(t *testing.T) {
 func TestGetCSSServers(t *testing.T) {
 	var id uint64 = appid.CounterStrikeSource.Uint64()
 
-	filter := &Filter{}
-	filter.Add(KeyAppID, fmt.Sprintf("%d", id))
-	filter.Add(KeyMap, "de_dust2")
+	filter := &serverlist.Filter{}
+	filter.Add(serverlist.KeyAppID, fmt.Sprintf("%d", id))
+	filter.Add(serverlist.KeyMap, "de_dust2")
 
 	if err := helperGetServers(id, filter, 50); err != nil {
 		t.Error(err)
@@ -40,10 +42,10 @@ func TestGetCSSServers(t *testing.T) {
 func TestGetDayZServers(t *testing.T) {
 	var id uint64 = appid.DayZ.Uint64()
 
-	filter := &Filter{}
-	filter.Add(KeyAppID, fmt.Sprintf("%d", id))
-	filter.Add(KeyGameType, "battleye")
-	filter.AddNor(KeyGameType, "external")
+	filter := &serverlist.Filter{}
+	filter.Add(serverlist.KeyAppID, fmt.Sprintf("%d", id))
+	filter.Add(serverlist.KeyGameType, "battleye")
+	filter.AddNor(serverlist.KeyGameType, "external")
 
 	if err := helperGetServers(id, filter, 200); err != nil {
 		t.Error(err)
@@ -54,24 +56,30 @@ func TestGetDayZServers(t *testing.T) {
 func TestGetArma3Servers(t *testing.T) {
 	var id uint64 = appid.Arma3.Uint64()
 
-	filter := &Filter{}
-	filter.Add(KeyAppID, fmt.Sprintf("%d", id))
-	filter.Add(KeyName, "\xb6 [ OFFICIAL ] Arma 3 *")
-	filter.Add(KeyGameType, "bt")
-	filter.Add(KeyGameType, "dt")
+	filter := &serverlist.Filter{}
+	filter.Add(serverlist.KeyAppID, fmt.Sprintf("%d", id))
+	filter.Add(serverlist.KeyName, "\xb6 [ OFFICIAL ] Arma 3 *")
+	filter.Add(serverlist.KeyGameType, "bt")
+	filter.Add(serverlist.KeyGameType, "dt")
 
 	if err := helperGetServers(id, filter, 150); err != nil {
 		t.Error(err)
 	}
 }
 
-func helperGetServers(appId uint64, filter *Filter, limit int) error {
-	key, ok := os.LookupEnv("STEAM_API_KEY")
-	if !ok {
-		return fmt.Errorf("Steam API key must be pass in variable 'STEAM_API_KEY'")
+func helperGetServers(appId uint64, filter *serverlist.Filter, limit int) error {
+	var query *serverlist.SteamQuery
+	if key, ok := os.LookupEnv("STEAM_API_KEY"); ok {
+		query = serverlist.New(key)
+	} else {
+		srv := steamtest.New()
+		defer srv.Close()
+		srv.AddServers(testServers()...)
+
+		query = serverlist.New(steamtest.Key)
+		query.SetClient(srv.Client())
 	}
 
-	query := New(key)
 	query.SetLimit(limit)
 	servers, err := query.Get(filter)
 
@@ -104,3 +112,39 @@ func helperGetServers(appId uint64, filter *Filter, limit int) error {
 
 	return nil
 }
+
+// testServers returns fixtures for the steamtest fake server used without STEAM_API_KEY
+func testServers() serverlist.Servers {
+	return serverlist.Servers{
+		{
+			Addr: "10.0.0.1:27015", GamePort: 27015, Appid: appid.CounterStrike2.Uint64(), GameDir: "csgo",
+			Name: "CS2 Dust", Map: "de_dust2", Version: "1.40.5.4", OS: "l", Players: 12, MaxPlayers: 20,
+			Dedicated: true, Secure: true, GameType: serverlist.GameType{"secure"},
+		},
+		{
+			Addr: "10.0.0.1:27016", GamePort: 27016, Appid: appid.CounterStrike2.Uint64(), GameDir: "csgo",
+			Name: "CS2 Insecure", Map: "de_dust2", Version: "1.40.5.4", OS: "l", Players: 3, MaxPlayers: 20,
+			Dedicated: true, GameType: serverlist.GameType{"insecure"},
+		},
+		{
+			Addr: "10.0.0.2:27015", GamePort: 27015, Appid: appid.CounterStrikeSource.Uint64(), GameDir: "cstrike",
+			Name: "CSS Dust", Map: "de_dust2", Version: "9540945", OS: "w", Players: 5, MaxPlayers: 32,
+			Dedicated: true, Secure: true,
+		},
+		{
+			Addr: "10.0.0.3:27016", GamePort: 2302, Appid: appid.DayZ.Uint64(), GameDir: "dayz",
+			Name: "DayZ Official 1", Map: "chernarusplus", Version: "1.26.159040", OS: "w", Players: 40, MaxPlayers: 60,
+			Dedicated: true, Secure: true, GameType: serverlist.GameType{"battleye", "lqs0", "etm2.000000"},
+		},
+		{
+			Addr: "10.0.0.4:27016", GamePort: 2302, Appid: appid.DayZ.Uint64(), GameDir: "dayz",
+			Name: "DayZ Community", Map: "enoch", Version: "1.26.159040", OS: "l", Players: 7, MaxPlayers: 60,
+			Dedicated: true, Secure: true, GameType: serverlist.GameType{"battleye", "external", "mod"},
+		},
+		{
+			Addr: "10.0.0.5:2303", GamePort: 2302, Appid: appid.Arma3.Uint64(), GameDir: "Arma3",
+			Name: "\xb6 [ OFFICIAL ] Arma 3 Zeus #1", Map: "Altis", Version: "2.18.152405", OS: "w", Players: 15,
+			MaxPlayers: 40, Dedicated: true, Secure: true, GameType: serverlist.GameType{"bt", "r218", "dt"},
+		},
+	}
+}
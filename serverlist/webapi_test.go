@@ -0,0 +1,143 @@
+package serverlist_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/steamtest"
+	"github.com/woozymasta/steam/utils/webapi"
+)
+
+// recorder collects observed requests
+type recorder struct {
+	infos []webapi.RequestInfo
+	mu    sync.Mutex
+}
+
+func (r *recorder) ObserveRequest(info webapi.RequestInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.infos = append(r.infos, info)
+}
+
+// failTransport fails every request
+type failTransport struct{}
+
+func (failTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestObserver(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+	srv.AddServers(serverlist.Server{Addr: "10.0.0.1:27016", Appid: 221100})
+
+	rec := &recorder{}
+	query := serverlist.New(steamtest.Key)
+	query.SetClient(srv.Client())
+	query.SetObserver(rec)
+	query.SetLimit(100)
+
+	if _, err := query.Get(&serverlist.Filter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.infos) != 1 {
+		t.Fatalf("observer called %d times, expected 1", len(rec.infos))
+	}
+	info := rec.infos[0]
+	if info.Status != http.StatusOK || info.ChunkSize != 100 || info.Bytes == 0 || info.Err != nil || info.Latency <= 0 {
+		t.Errorf("server list request: %+v", info)
+	}
+	if !strings.HasSuffix(info.Endpoint, "/IGameServersService/GetServerList/v1/") {
+		t.Errorf("unexpected endpoint %s", info.Endpoint)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	srv := steamtest.New()
+	defer srv.Close()
+	srv.AddServers(serverlist.Server{Addr: "10.0.0.1:27016", Appid: 221100})
+
+	// First key is rejected by the server, second one gets rate limited once
+	bad := "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"
+	pool := webapi.NewKeyPool(bad, steamtest.Key)
+	rec := &recorder{}
+
+	query := serverlist.New("")
+	query.SetClient(srv.Client())
+	query.SetKeyProvider(pool)
+	query.SetObserver(rec)
+
+	if _, err := query.Get(&serverlist.Filter{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.infos) != 2 || rec.infos[0].Status != http.StatusForbidden || rec.infos[1].Retries != 1 {
+		t.Errorf("requests %+v", rec.infos)
+	}
+
+	srv.RateLimit(1)
+	if _, err := query.Get(&serverlist.Filter{}); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("Get() with rate limited key returned %v", err)
+	}
+	if _, err := query.Get(&serverlist.Filter{}); !errors.Is(err, webapi.ErrNoKeys) {
+		t.Errorf("Get() with all keys benched returned %v", err)
+	}
+
+	usage := pool.Usage()
+	if usage[0].BenchedUntil.IsZero() || usage[1].BenchedUntil.IsZero() {
+		t.Errorf("keys must be benched: %+v", usage)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	rec := &recorder{}
+
+	query := serverlist.New(steamtest.Key)
+	query.SetClient(&http.Client{Transport: failTransport{}})
+	query.SetLogger(logger)
+	query.SetObserver(rec)
+	_, err := query.Get(&serverlist.Filter{})
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("error lost details: %v", err)
+	}
+
+	// Invalid base URL fails before the request is sent
+	invalid := serverlist.New(steamtest.Key)
+	invalid.SetBaseURL("://invalid")
+	_, ierr := invalid.Get(&serverlist.Filter{})
+
+	for _, err := range []error{err, ierr} {
+		if err == nil {
+			t.Fatal("request must fail")
+		}
+		if strings.Contains(err.Error(), steamtest.Key) {
+			t.Errorf("error contains API key: %v", err)
+		}
+		if !strings.Contains(err.Error(), "key=") {
+			t.Errorf("error lost details: %v", err)
+		}
+		var uerr *url.Error
+		if !errors.As(err, &uerr) || strings.Contains(uerr.URL, steamtest.Key) {
+			t.Errorf("url.Error is not redacted: %v", err)
+		}
+	}
+
+	for _, info := range rec.infos {
+		if strings.Contains(info.Err.Error(), steamtest.Key) {
+			t.Errorf("observed error contains API key: %v", info.Err)
+		}
+	}
+	if strings.Contains(logs.String(), steamtest.Key) {
+		t.Errorf("logs contain API key: %s", logs.String())
+	}
+}
@@ -0,0 +1,148 @@
+package serverlist
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/woozymasta/steam/utils/latest"
+)
+
+// Predicate reports whether the server matches a condition which can not be expressed
+// in the master server filter, e.g. player ratio or version range.
+type Predicate func(s *Server) bool
+
+// Where returns servers matching all the predicates.
+func (s Servers) Where(predicates ...Predicate) Servers {
+	match := All(predicates...)
+
+	var result Servers
+	for i := range s {
+		if match(&s[i]) {
+			result = append(result, s[i])
+		}
+	}
+
+	return result
+}
+
+// All returns the predicate matching servers matching all the predicates.
+func All(predicates ...Predicate) Predicate {
+	return func(s *Server) bool {
+		for _, p := range predicates {
+			if !p(s) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Any returns the predicate matching servers matching any of the predicates.
+func Any(predicates ...Predicate) Predicate {
+	return func(s *Server) bool {
+		for _, p := range predicates {
+			if p(s) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not returns the predicate matching servers not matching the predicate.
+func Not(p Predicate) Predicate {
+	return func(s *Server) bool {
+		return !p(s)
+	}
+}
+
+// PlayersAtLeast matches servers with at least n players.
+func PlayersAtLeast(n int) Predicate {
+	return func(s *Server) bool {
+		return int(s.Players) >= n
+	}
+}
+
+// PlayerRatioAtLeast matches servers filled at least by the ratio in range 0..1.
+func PlayerRatioAtLeast(ratio float64) Predicate {
+	return func(s *Server) bool {
+		return playerRatio(s) >= ratio
+	}
+}
+
+// BotsAtMost matches servers with at most n bots.
+func BotsAtMost(n int) Predicate {
+	return func(s *Server) bool {
+		return int(s.Bots) <= n
+	}
+}
+
+// NotFull matches servers with free slots.
+func NotFull() Predicate {
+	return func(s *Server) bool {
+		return s.Players < s.MaxPlayers
+	}
+}
+
+// VersionAtLeast matches servers with the version not older than the version,
+// versions are compared with latest.CompareVersions.
+func VersionAtLeast(version string) Predicate {
+	return func(s *Server) bool {
+		return latest.CompareVersions(s.Version, version) >= 0
+	}
+}
+
+// HasGameType matches servers having all the game type tags, tags are compared case-insensitively.
+func HasGameType(tags ...string) Predicate {
+	return func(s *Server) bool {
+		for _, tag := range tags {
+			if !s.GameType.has(tag) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// NameRegexp matches servers with the name matching the regular expression.
+func NameRegexp(re *regexp.Regexp) Predicate {
+	return func(s *Server) bool {
+		return re.MatchString(s.Name)
+	}
+}
+
+// InRegion matches servers in any of the regions.
+func InRegion(regions ...Region) Predicate {
+	return func(s *Server) bool {
+		for _, r := range regions {
+			if s.Region == r {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// has reports whether the game type has the tag
+func (gt GameType) has(tag string) bool {
+	for _, t := range gt {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// playerRatio returns players to max players ratio
+func playerRatio(s *Server) float64 {
+	if s.MaxPlayers == 0 {
+		return 0
+	}
+
+	return float64(s.Players) / float64(s.MaxPlayers)
+}
@@ -0,0 +1,129 @@
+package serverlist_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/woozymasta/steam/serverlist"
+)
+
+func whereServers() serverlist.Servers {
+	return serverlist.Servers{
+		{Name: "DayZ Official 1", Version: "1.26.159040", OS: "w", Map: "chernarusplus", Region: 3,
+			Players: 55, MaxPlayers: 60, Dedicated: true, GameType: serverlist.GameType{"battleye", "lqs0"}},
+		{Name: "Test server", Version: "1.25.158593", OS: "l", Map: "enoch", Region: 0,
+			Players: 2, MaxPlayers: 10, Bots: 5, Dedicated: true, GameType: serverlist.GameType{"external"}},
+		{Name: "DayZ Full", Version: "1.26.159040", OS: "w", Map: "chernarusplus", Region: 3,
+			Players: 60, MaxPlayers: 60, Secure: true, GameType: serverlist.GameType{"BattlEye"}},
+	}
+}
+
+func names(servers serverlist.Servers) []string {
+	result := make([]string, len(servers))
+	for i, s := range servers {
+		result[i] = s.Name
+	}
+
+	return result
+}
+
+func TestWhere(t *testing.T) {
+	servers := whereServers()
+
+	cases := map[string]struct {
+		predicate serverlist.Predicate
+		want      int
+	}{
+		"players":   {serverlist.PlayersAtLeast(50), 2},
+		"ratio":     {serverlist.PlayerRatioAtLeast(0.5), 2},
+		"bots":      {serverlist.BotsAtMost(0), 2},
+		"not full":  {serverlist.NotFull(), 2},
+		"version":   {serverlist.VersionAtLeast("1.26"), 2},
+		"gametype":  {serverlist.HasGameType("battleye"), 2},
+		"name":      {serverlist.NameRegexp(regexp.MustCompile(`^DayZ`)), 2},
+		"region":    {serverlist.InRegion(serverlist.RegionUSEast, serverlist.RegionAsia), 1},
+		"any":       {serverlist.Any(serverlist.InRegion(serverlist.RegionUSEast), serverlist.PlayersAtLeast(60)), 2},
+		"not":       {serverlist.Not(serverlist.NotFull()), 1},
+		"composite": {serverlist.All(serverlist.VersionAtLeast("1.26"), serverlist.NotFull()), 1},
+	}
+
+	for name, c := range cases {
+		if got := servers.Where(c.predicate); len(got) != c.want {
+			t.Errorf("%s: Where() = %v, expected %d servers", name, names(got), c.want)
+		}
+	}
+
+	if got := servers.Where(serverlist.PlayersAtLeast(1), serverlist.NotFull()); len(got) != 2 {
+		t.Errorf("Where() with several predicates = %v", names(got))
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	servers := whereServers()
+
+	cases := map[string][]string{
+		`players >= 50 and version >= "1.26"`:              {"DayZ Official 1", "DayZ Full"},
+		`ratio < 0.5 || free = 0`:                          {"Test server", "DayZ Full"},
+		`not (name ~ "(?i)test" or gametype has external)`: {"DayZ Official 1", "DayZ Full"},
+		`os = L`: {"Test server"},
+		`!secure = true && dedicated == true && bots <= 0`:   {"DayZ Official 1"},
+		`version < 1.26 or (map != chernarusplus)`:           {"Test server"},
+		`name !~ "Full$" and region = 3 and max_players > 0`: {"DayZ Official 1"},
+		`players>=50&&!(name~Full)`:                          {"DayZ Official 1"},
+		`region == Europe`:                                   {"DayZ Official 1", "DayZ Full"},
+		`region = "us east" or region > Europe`:              {"Test server"},
+	}
+
+	for rule, want := range cases {
+		pred, err := serverlist.ParseRule(rule)
+		if err != nil {
+			t.Errorf("ParseRule(%q): %v", rule, err)
+			continue
+		}
+
+		got := names(servers.Where(pred))
+		if len(got) != len(want) {
+			t.Errorf("ParseRule(%q) matched %v, expected %v", rule, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("ParseRule(%q) matched %v, expected %v", rule, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	cases := map[string]int{
+		`unknown = 1`:            0,
+		`players`:                7,
+		`players >= ten`:         11,
+		`players ~ 1`:            8,
+		`name < a`:               5,
+		`secure = maybe`:         9,
+		`gametype = battleye`:    9,
+		`name ~ "("`:             7,
+		`name = "open`:           7,
+		`(players > 1`:           12,
+		`players > 1 players`:    12,
+		`players => 1`:           9,
+		`players > 1 and and`:    16,
+		`dedicated = true extra`: 17,
+		`region = Mars`:          9,
+	}
+
+	for rule, offset := range cases {
+		_, err := serverlist.ParseRule(rule)
+		var perr *serverlist.ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("ParseRule(%q) = %v, expected ParseError", rule, err)
+			continue
+		}
+		if perr.Offset != offset {
+			t.Errorf("ParseRule(%q) offset = %d, expected %d (%v)", rule, perr.Offset, offset, err)
+		}
+	}
+}
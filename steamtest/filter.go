@@ -0,0 +1,260 @@
+package steamtest
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/woozymasta/steam/serverlist"
+)
+
+// node is a condition or a counted group (and, or, nor, nand) of the master server filter
+type node struct {
+	op    string
+	key   string
+	value string
+	nodes []*node
+}
+
+// filter is a parsed master server filter
+type filter struct {
+	root     *node
+	collapse bool
+}
+
+// parseFilter parses the backslash separated filter, groups take the next N conditions,
+// a nested group counts as one condition of the parent group
+func parseFilter(s string) (*filter, error) {
+	s = strings.TrimPrefix(s, "\\")
+	f := &filter{root: &node{op: "and"}}
+	if s == "" {
+		return f, nil
+	}
+
+	tokens := strings.Split(s, "\\")
+	if len(tokens)%2 != 0 {
+		return nil, fmt.Errorf("filter %q has key without value", s)
+	}
+
+	pos := 0
+	var term func() (*node, error)
+	term = func() (*node, error) {
+		if pos+1 >= len(tokens) {
+			return nil, fmt.Errorf("filter %q ends before group is complete", s)
+		}
+		key, value := tokens[pos], tokens[pos+1]
+		pos += 2
+
+		switch key {
+		case "and", "or", "nor", "nand":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("bad %s count %q", key, value)
+			}
+			n := &node{op: key}
+			for i := 0; i < count; i++ {
+				child, err := term()
+				if err != nil {
+					return nil, err
+				}
+				n.nodes = append(n.nodes, child)
+			}
+			return n, nil
+
+		case string(serverlist.KeySingleAddr):
+			f.collapse = value != "0"
+		}
+
+		return &node{key: key, value: value}, nil
+	}
+
+	for pos < len(tokens) {
+		n, err := term()
+		if err != nil {
+			return nil, err
+		}
+		f.root.nodes = append(f.root.nodes, n)
+	}
+
+	return f, nil
+}
+
+// match reports whether the server passes the filter
+func (f *filter) match(s *serverlist.Server) bool {
+	return f.root.match(s)
+}
+
+func (n *node) match(s *serverlist.Server) bool {
+	switch n.op {
+	case "and":
+		for _, c := range n.nodes {
+			if !c.match(s) {
+				return false
+			}
+		}
+		return true
+	case "or":
+		for _, c := range n.nodes {
+			if c.match(s) {
+				return true
+			}
+		}
+		return false
+	case "nor":
+		for _, c := range n.nodes {
+			if c.match(s) {
+				return false
+			}
+		}
+		return true
+	case "nand":
+		for _, c := range n.nodes {
+			if !c.match(s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return matchCondition(serverlist.FilterKey(n.key), n.value, s)
+}
+
+// matchCondition reports whether the server matches a single key\value condition,
+// keys without data in the fixture (e.g. white, gamedata) never match, servers have no password
+func matchCondition(key serverlist.FilterKey, value string, s *serverlist.Server) bool {
+	flag := value != "0"
+
+	switch key {
+	case serverlist.KeyAppID:
+		return value == strconv.FormatUint(s.Appid, 10)
+	case serverlist.KeyNotAppID:
+		return value != strconv.FormatUint(s.Appid, 10)
+	case serverlist.KeyGameDir:
+		return strings.EqualFold(value, s.GameDir)
+	case serverlist.KeyMap:
+		return strings.EqualFold(value, s.Map)
+	case serverlist.KeyDedicated:
+		return s.Dedicated == flag
+	case serverlist.KeySecure:
+		return s.Secure == flag
+	case serverlist.KeyLinux:
+		return (s.OS == "l") == flag
+	case serverlist.KeyEmpty:
+		return (s.Players > 0) == flag
+	case serverlist.KeyFull:
+		return (s.Players < s.MaxPlayers) == flag
+	case serverlist.KeyNoPlayers:
+		return (s.Players == 0) == flag
+	case serverlist.KeyGameType, serverlist.KeyGameTagsAnd:
+		return hasTags(s.GameType, value)
+	case serverlist.KeyGameTagsNor:
+		return !hasAnyTag(s.GameType, value)
+	case serverlist.KeyPassword:
+		return !flag
+	case serverlist.KeyRegion:
+		return value == strconv.Itoa(int(s.Region))
+	case serverlist.KeySteamID:
+		return value == strconv.FormatUint(s.SteamID, 10)
+	case serverlist.KeyName:
+		return wildcard(value, s.Name)
+	case serverlist.KeyVersion:
+		return wildcard(value, s.Version)
+	case serverlist.KeyGameAddr:
+		return matchAddr(value, s)
+	case serverlist.KeySingleAddr:
+		return true
+	}
+
+	return false
+}
+
+// hasTags reports whether all comma separated tags are in the game type
+func hasTags(gameType []string, tags string) bool {
+	for _, tag := range strings.Split(tags, ",") {
+		found := false
+		for _, t := range gameType {
+			if strings.EqualFold(t, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasAnyTag reports whether any of comma separated tags is in the game type
+func hasAnyTag(gameType []string, tags string) bool {
+	for _, tag := range strings.Split(tags, ",") {
+		if hasTags(gameType, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchAddr matches "ip" or "ip:port" against the server address or game port
+func matchAddr(value string, s *serverlist.Server) bool {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return value == addrHost(s.Addr)
+	}
+	if host != addrHost(s.Addr) {
+		return false
+	}
+
+	_, addrPort, _ := net.SplitHostPort(s.Addr)
+	return port == addrPort || port == strconv.Itoa(int(s.GamePort))
+}
+
+// addrHost returns IP of the "IP:Port" address
+func addrHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+// wildcard matches the case-insensitive pattern with "*" wildcards
+func wildcard(pattern, s string) bool {
+	pattern, s = asciiLower(pattern), asciiLower(s)
+
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, p)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(p):]
+	}
+
+	return strings.HasSuffix(s, last)
+}
+
+// asciiLower lowers ASCII letters only, other bytes are kept as is
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+
+	return string(b)
+}
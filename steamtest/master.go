@@ -0,0 +1,175 @@
+package steamtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+
+	"github.com/woozymasta/steam/serverlist"
+)
+
+// DefaultPageSize is the number of addresses in a reply of the fake master server,
+// the same as in a full reply of the Steam master server.
+const DefaultPageSize = 231
+
+// MasterRequest is a request received by the fake master server.
+type MasterRequest struct {
+	Seed   string            // Seed address
+	Filter string            // Filter string
+	Region serverlist.Region // Requested region
+}
+
+// Master is a fake master server speaking the UDP Master Server Query Protocol,
+// replies are built from servers added with AddServers and filtered like GetServerList of Server.
+type Master struct {
+	conn     *net.UDPConn
+	requests []MasterRequest
+	servers  serverlist.Servers
+	pageSize int
+	mu       sync.Mutex
+}
+
+// NewMaster starts a new fake master server on a local UDP port. The caller should call Close when finished.
+func NewMaster() *Master {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		panic(fmt.Sprintf("steamtest: failed to listen on a port: %v", err))
+	}
+
+	m := &Master{conn: conn, pageSize: DefaultPageSize}
+	go m.serve()
+
+	return m
+}
+
+// Addr returns the "IP:Port" address of the fake master server for serverlist.MasterQuery.SetAddr.
+func (m *Master) Addr() string {
+	return m.conn.LocalAddr().String()
+}
+
+// Close stops the fake master server.
+func (m *Master) Close() error {
+	return m.conn.Close()
+}
+
+// AddServers adds servers returned by the fake master server, Addr must be an IPv4 "IP:Port".
+func (m *Master) AddServers(servers ...serverlist.Server) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers = append(m.servers, servers...)
+}
+
+// SetPageSize sets the number of addresses in a single reply.
+func (m *Master) SetPageSize(size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pageSize = max(size, 1)
+}
+
+// Requests returns a copy of all requests received by the fake master server.
+func (m *Master) Requests() []MasterRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MasterRequest(nil), m.requests...)
+}
+
+// serve answers requests until the connection is closed, malformed requests are ignored
+func (m *Master) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := m.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		request, ok := parseMasterRequest(buf[:n])
+		if !ok {
+			continue
+		}
+
+		reply, err := m.reply(request)
+		if err != nil {
+			continue
+		}
+		_, _ = m.conn.WriteToUDP(reply, addr)
+	}
+}
+
+// reply returns the page of addresses following the seed, the last page ends with 0.0.0.0:0
+func (m *Master) reply(request MasterRequest) ([]byte, error) {
+	filter, err := parseFilter(request.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, request)
+
+	var addrs []netip.AddrPort
+	collapsed := make(map[string]struct{})
+	for i := range m.servers {
+		srv := &m.servers[i]
+		if request.Region != serverlist.RegionWorld && srv.Region != request.Region {
+			continue
+		}
+		if !filter.match(srv) {
+			continue
+		}
+		if filter.collapse {
+			ip := addrHost(srv.Addr)
+			if _, ok := collapsed[ip]; ok {
+				continue
+			}
+			collapsed[ip] = struct{}{}
+		}
+		if addr, err := netip.ParseAddrPort(srv.Addr); err == nil && addr.Addr().Is4() {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	start := 0
+	if seed, _ := netip.ParseAddrPort(request.Seed); seed.Port() != 0 {
+		for i, addr := range addrs {
+			if addr == seed {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	end := min(start+m.pageSize, len(addrs))
+	page := addrs[start:end]
+	if end == len(addrs) {
+		page = append(page[:len(page):len(page)], netip.AddrPortFrom(netip.IPv4Unspecified(), 0))
+	}
+
+	reply := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x66, 0x0A}
+	for _, addr := range page {
+		ip := addr.Addr().As4()
+		reply = binary.BigEndian.AppendUint16(append(reply, ip[:]...), addr.Port())
+	}
+
+	return reply, nil
+}
+
+// parseMasterRequest decodes the request: 0x31, region, seed and filter strings
+func parseMasterRequest(b []byte) (MasterRequest, bool) {
+	if len(b) < 2 || b[0] != 0x31 {
+		return MasterRequest{}, false
+	}
+
+	parts := bytes.SplitN(b[2:], []byte{0}, 3)
+	if len(parts) < 3 {
+		return MasterRequest{}, false
+	}
+
+	return MasterRequest{
+		Region: serverlist.Region(b[1]),
+		Seed:   string(parts[0]),
+		Filter: string(parts[1]),
+	}, true
+}
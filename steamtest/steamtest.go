@@ -0,0 +1,591 @@
+/*
+Package steamtest provides an in-process fake of the Steam Web API for offline tests.
+
+The fake server implements the endpoints used by this module:
+  - IPublishedFileService/GetDetails for the filedetails package
+  - ISteamRemoteStorage/GetPublishedFileDetails, the keyless endpoint of the filedetails package
+  - IGameServersService/GetServerList for the serverlist package, including the backslash filter syntax
+
+The UDP Master Server Query Protocol of serverlist.MasterQuery is served by Master started with NewMaster.
+
+Responses are built from fixture data added with AddFiles, AddServers or loaded from
+captured API responses with LoadFiles and LoadServers. Errors, rate limits and latency
+can be injected, and every received request is recorded.
+
+# Example usage:
+
+	func TestMods(t *testing.T) {
+		srv := steamtest.New()
+		defer srv.Close()
+
+		srv.AddFiles(filedetails.FileDetail{PublishedFileID: 1559212036, ConsumerAppID: 221100, Title: "CF"})
+
+		query := filedetails.New([]uint64{1559212036}, steamtest.Key)
+		query.SetClient(srv.Client())
+
+		files, err := query.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// ...
+	}
+*/
+package steamtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/woozymasta/steam/filedetails"
+	"github.com/woozymasta/steam/serverlist"
+)
+
+// Key is the default API key accepted by the fake server.
+const Key = "0123456789ABCDEF0123456789ABCDEF"
+
+const (
+	// PathGetDetails is the path of the IPublishedFileService/GetDetails endpoint.
+	PathGetDetails = "/IPublishedFileService/GetDetails/v1/"
+	// PathGetPublishedFileDetails is the path of the keyless ISteamRemoteStorage/GetPublishedFileDetails endpoint.
+	PathGetPublishedFileDetails = "/ISteamRemoteStorage/GetPublishedFileDetails/v1/"
+	// PathGetServerList is the path of the IGameServersService/GetServerList endpoint.
+	PathGetServerList = "/IGameServersService/GetServerList/v1/"
+
+	// resultFileNotFound is the EResult k_EResultFileNotFound returned for unknown files
+	resultFileNotFound = 9
+)
+
+// Request is a request received by the fake server.
+type Request struct {
+	Time   time.Time   // Time the request was received
+	Query  url.Values  // Parsed query string
+	Form   url.Values  // Parsed POST form, nil for other methods
+	Header http.Header // Request headers
+	Method string      // HTTP method
+	Path   string      // URL path
+	Status int         // Status code of the response
+}
+
+// Server is a fake Steam Web API server.
+type Server struct {
+	*httptest.Server
+
+	files    map[uint64]filedetails.FileDetail
+	inject   []http.HandlerFunc
+	requests []Request
+	servers  serverlist.Servers
+	key      string
+	latency  time.Duration
+	mu       sync.Mutex
+}
+
+// New starts a new fake server accepting the Key API key. The caller should call Close when finished.
+func New() *Server {
+	s := &Server{
+		files: make(map[uint64]filedetails.FileDetail),
+		key:   Key,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathGetDetails, s.handleGetDetails)
+	mux.HandleFunc(PathGetPublishedFileDetails, s.handleGetPublishedFileDetails)
+	mux.HandleFunc(PathGetServerList, s.handleGetServerList)
+	s.Server = httptest.NewServer(s.middleware(mux))
+
+	return s
+}
+
+// Client returns an HTTP client sending all requests to the fake server, whatever host is in the URL.
+// It can be passed to filedetails.Query.SetClient and serverlist.SteamQuery.SetClient.
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.URL)
+
+	return &http.Client{
+		Transport: &rewriteTransport{target: target, base: s.Server.Client().Transport},
+		Timeout:   30 * time.Second,
+	}
+}
+
+// SetKey sets the API key accepted by the fake server, an empty key disables the key check.
+func (s *Server) SetKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+}
+
+// SetLatency sets a delay applied to every response.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// AddFiles adds workshop items returned by GetDetails, items with the same ID are replaced.
+func (s *Server) AddFiles(files ...filedetails.FileDetail) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range files {
+		s.files[f.PublishedFileID] = f
+	}
+}
+
+// AddServers adds game servers returned by GetServerList.
+func (s *Server) AddServers(servers ...serverlist.Server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.servers = append(s.servers, servers...)
+}
+
+// LoadFiles adds workshop items from a captured GetDetails JSON response.
+func (s *Server) LoadFiles(r io.Reader) error {
+	var result struct {
+		Response struct {
+			Details []filedetails.FileDetail `json:"publishedfiledetails"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return err
+	}
+
+	s.AddFiles(result.Response.Details...)
+	return nil
+}
+
+// LoadServers adds game servers from a captured GetServerList JSON response.
+func (s *Server) LoadServers(r io.Reader) error {
+	var result struct {
+		Response struct {
+			Servers serverlist.Servers `json:"servers"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return err
+	}
+
+	s.AddServers(result.Response.Servers...)
+	return nil
+}
+
+// Inject makes the next count requests to be handled by h instead of the API endpoints.
+func (s *Server) Inject(h http.HandlerFunc, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < count; i++ {
+		s.inject = append(s.inject, h)
+	}
+}
+
+// FailNext makes the next count requests fail with the status code.
+func (s *Server) FailNext(status, count int) {
+	s.Inject(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, http.StatusText(status), status)
+	}, count)
+}
+
+// RateLimit makes the next count requests fail with 429 Too Many Requests.
+func (s *Server) RateLimit(count int) {
+	s.Inject(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	}, count)
+}
+
+// Requests returns a copy of all requests received by the fake server.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset clears recorded requests and pending injections.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.inject = nil
+}
+
+// middleware records requests, applies latency, injected handlers and the key check
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := Request{
+			Time:   time.Now(),
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Header: r.Header.Clone(),
+		}
+		if r.Method == http.MethodPost {
+			if err := r.ParseForm(); err == nil {
+				rec.Form = r.PostForm
+			}
+		}
+
+		s.mu.Lock()
+		latency, key := s.latency, s.key
+		var h http.Handler = next
+		if len(s.inject) > 0 {
+			h = s.inject[0]
+			s.inject = s.inject[1:]
+		} else if key != "" && r.URL.Path != PathGetPublishedFileDetails && rec.Query.Get("key") != key {
+			h = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "<html><head><title>Forbidden</title></head></html>", http.StatusForbidden)
+			})
+		}
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r)
+
+		rec.Status = sw.status
+		s.mu.Lock()
+		s.requests = append(s.requests, rec)
+		s.mu.Unlock()
+	})
+}
+
+// handleGetDetails implements IPublishedFileService/GetDetails
+func (s *Server) handleGetDetails(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	// publishedfileids[N] in the order of N
+	type indexed struct {
+		idx int
+		id  uint64
+	}
+	var ids []indexed
+	for k, v := range q {
+		if !strings.HasPrefix(k, "publishedfileids[") || !strings.HasSuffix(k, "]") || len(v) == 0 {
+			continue
+		}
+		idx, err := strconv.Atoi(k[len("publishedfileids[") : len(k)-1])
+		if err != nil {
+			http.Error(w, "bad index "+k, http.StatusBadRequest)
+			return
+		}
+		id, err := strconv.ParseUint(v[0], 10, 64)
+		if err != nil {
+			http.Error(w, "bad id "+v[0], http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, indexed{idx: idx, id: id})
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].idx < ids[j].idx })
+
+	flag := func(name string) bool { return q.Get(name) == "true" || q.Get(name) == "1" }
+
+	s.mu.Lock()
+	details := make([]wireFileDetail, 0, len(ids))
+	for _, i := range ids {
+		f, ok := s.files[i.id]
+		if !ok {
+			f = filedetails.FileDetail{PublishedFileID: i.id, Result: resultFileNotFound}
+		} else if f.Result == 0 {
+			f.Result = 1
+		}
+
+		if !flag("includetags") {
+			f.Tags = nil
+		}
+		if !flag("includeadditionalpreviews") {
+			f.Previews = nil
+		}
+		if !flag("includechildren") {
+			f.Children = nil
+		}
+		if !flag("includekvtags") {
+			f.KVTags = nil
+		}
+		if !flag("includevotes") {
+			f.VoteData = nil
+		}
+		if !flag("includereactions") {
+			f.Reactions = nil
+		}
+
+		details = append(details, newWireFileDetail(f))
+	}
+	s.mu.Unlock()
+
+	var result struct {
+		Response struct {
+			Details []wireFileDetail `json:"publishedfiledetails"`
+		} `json:"response"`
+	}
+	result.Response.Details = details
+
+	writeJSON(w, result)
+}
+
+// handleGetPublishedFileDetails implements ISteamRemoteStorage/GetPublishedFileDetails
+func (s *Server) handleGetPublishedFileDetails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count, err := strconv.Atoi(r.PostForm.Get("itemcount"))
+	if err != nil {
+		http.Error(w, "bad itemcount", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	details := make([]wireRemoteStorageDetail, 0, count)
+	for i := 0; i < count; i++ {
+		id, err := strconv.ParseUint(r.PostForm.Get("publishedfileids["+strconv.Itoa(i)+"]"), 10, 64)
+		if err != nil {
+			s.mu.Unlock()
+			http.Error(w, "bad publishedfileids", http.StatusBadRequest)
+			return
+		}
+
+		f, ok := s.files[id]
+		if !ok {
+			details = append(details, wireRemoteStorageDetail{PublishedFileID: strconv.FormatUint(id, 10), Result: resultFileNotFound})
+			continue
+		}
+		details = append(details, newWireRemoteStorageDetail(f))
+	}
+	s.mu.Unlock()
+
+	var result struct {
+		Response struct {
+			Details     []wireRemoteStorageDetail `json:"publishedfiledetails"`
+			Result      int                       `json:"result"`
+			ResultCount int                       `json:"resultcount"`
+		} `json:"response"`
+	}
+	result.Response.Result = 1
+	result.Response.ResultCount = len(details)
+	result.Response.Details = details
+
+	writeJSON(w, result)
+}
+
+// handleGetServerList implements IGameServersService/GetServerList
+func (s *Server) handleGetServerList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter, err := parseFilter(q.Get("filter"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := serverlist.DefaultLimit
+	if l := q.Get("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil {
+			http.Error(w, "bad limit "+l, http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	servers := make([]wireServer, 0)
+	collapsed := make(map[string]struct{})
+	for i := range s.servers {
+		if len(servers) >= limit {
+			break
+		}
+		srv := &s.servers[i]
+		if !filter.match(srv) {
+			continue
+		}
+		if filter.collapse {
+			ip := addrHost(srv.Addr)
+			if _, ok := collapsed[ip]; ok {
+				continue
+			}
+			collapsed[ip] = struct{}{}
+		}
+		servers = append(servers, newWireServer(*srv))
+	}
+	s.mu.Unlock()
+
+	var result struct {
+		Response struct {
+			Servers []wireServer `json:"servers,omitempty"`
+		} `json:"response"`
+	}
+	result.Response.Servers = servers
+
+	writeJSON(w, result)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	_, _ = w.Write(data)
+}
+
+// rewriteTransport sends every request to the target server
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	r.Host = t.target.Host
+
+	return t.base.RoundTrip(r)
+}
+
+// statusWriter remembers the response status code
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// fileDetailAlias drops FileDetail methods to use default encoding
+type fileDetailAlias filedetails.FileDetail
+
+// wireFileDetail is FileDetail in the Steam API format with unix timestamps
+type wireFileDetail struct {
+	*fileDetailAlias
+	TimeCreated int64 `json:"time_created"`
+	TimeUpdated int64 `json:"time_updated"`
+}
+
+func newWireFileDetail(f filedetails.FileDetail) wireFileDetail {
+	w := wireFileDetail{fileDetailAlias: (*fileDetailAlias)(&f)}
+	if !f.TimeCreated.IsZero() {
+		w.TimeCreated = f.TimeCreated.Unix()
+	}
+	if !f.TimeUpdated.IsZero() {
+		w.TimeUpdated = f.TimeUpdated.Unix()
+	}
+
+	return w
+}
+
+// serverAlias drops Server methods to use default encoding
+type serverAlias serverlist.Server
+
+// wireServer is Server in the Steam API format with comma separated game type and region code
+type wireServer struct {
+	*serverAlias
+	GameType string `json:"gametype"`
+	Region   int    `json:"region"`
+}
+
+func newWireServer(s serverlist.Server) wireServer {
+	return wireServer{
+		serverAlias: (*serverAlias)(&s),
+		GameType:    strings.Join(s.GameType, ","),
+		Region:      int(s.Region),
+	}
+}
+
+// wireRemoteStorageDetail is FileDetail in the ISteamRemoteStorage/GetPublishedFileDetails format
+type wireRemoteStorageDetail struct {
+	BanReason             string    `json:"ban_reason,omitempty"`
+	Creator               string    `json:"creator,omitempty"`
+	Description           string    `json:"description,omitempty"`
+	Filename              string    `json:"filename,omitempty"`
+	HContentFile          string    `json:"hcontent_file,omitempty"`
+	HContentPreview       string    `json:"hcontent_preview,omitempty"`
+	PreviewURL            string    `json:"preview_url,omitempty"`
+	PublishedFileID       string    `json:"publishedfileid"`
+	Title                 string    `json:"title,omitempty"`
+	Tags                  []wireTag `json:"tags,omitempty"`
+	ConsumerAppID         uint64    `json:"consumer_app_id,omitempty"`
+	CreatorAppID          uint64    `json:"creator_app_id,omitempty"`
+	FileSize              uint64    `json:"file_size,omitempty"`
+	TimeCreated           int64     `json:"time_created,omitempty"`
+	TimeUpdated           int64     `json:"time_updated,omitempty"`
+	Banned                int       `json:"banned"`
+	Favorited             int       `json:"favorited,omitempty"`
+	LifetimeFavorited     int       `json:"lifetime_favorited,omitempty"`
+	LifetimeSubscriptions int       `json:"lifetime_subscriptions,omitempty"`
+	Result                int       `json:"result"`
+	Subscriptions         int       `json:"subscriptions,omitempty"`
+	Views                 int       `json:"views,omitempty"`
+	Visibility            int       `json:"visibility"`
+}
+
+// wireTag is a tag in the ISteamRemoteStorage format
+type wireTag struct {
+	Tag string `json:"tag"`
+}
+
+func newWireRemoteStorageDetail(f filedetails.FileDetail) wireRemoteStorageDetail {
+	w := wireRemoteStorageDetail{
+		BanReason:             f.BanReason,
+		Description:           f.FileDescription,
+		Filename:              f.Filename,
+		PreviewURL:            f.PreviewURL,
+		PublishedFileID:       strconv.FormatUint(f.PublishedFileID, 10),
+		Title:                 f.Title,
+		ConsumerAppID:         f.ConsumerAppID,
+		CreatorAppID:          f.CreatorAppID,
+		FileSize:              f.FileSize,
+		Favorited:             f.Favorited,
+		LifetimeFavorited:     f.LifetimeFavorited,
+		LifetimeSubscriptions: f.LifetimeSubscriptions,
+		Result:                f.Result,
+		Subscriptions:         f.Subscriptions,
+		Views:                 f.Views,
+		Visibility:            f.Visibility,
+	}
+	if w.Result == 0 {
+		w.Result = 1
+	}
+	if f.Creator != 0 {
+		w.Creator = strconv.FormatUint(f.Creator, 10)
+	}
+	if f.HContentFile != 0 {
+		w.HContentFile = strconv.FormatUint(f.HContentFile, 10)
+	}
+	if f.HContentPreview != 0 {
+		w.HContentPreview = strconv.FormatUint(f.HContentPreview, 10)
+	}
+	if !f.TimeCreated.IsZero() {
+		w.TimeCreated = f.TimeCreated.Unix()
+	}
+	if !f.TimeUpdated.IsZero() {
+		w.TimeUpdated = f.TimeUpdated.Unix()
+	}
+	if f.Banned {
+		w.Banned = 1
+	}
+	for _, t := range f.Tags {
+		w.Tags = append(w.Tags, wireTag{Tag: t.Tag})
+	}
+
+	return w
+}
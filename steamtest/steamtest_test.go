@@ -0,0 +1,180 @@
+package steamtest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/woozymasta/steam/filedetails"
+	"github.com/woozymasta/steam/serverlist"
+)
+
+func testServers() []serverlist.Server {
+	return []serverlist.Server{
+		{Addr: "10.0.0.1:27016", GamePort: 2302, Appid: 221100, Name: "DayZ Official 1", Map: "chernarusplus",
+			SteamID: 90200000000000001, Region: 3, OS: "w", Dedicated: true, Secure: true, Players: 10, MaxPlayers: 60, GameType: []string{"battleye", "lqs0"}},
+		{Addr: "10.0.0.1:27017", GamePort: 2402, Appid: 221100, Name: "DayZ Community", Map: "enoch",
+			OS: "l", Dedicated: true, Secure: true, Players: 0, MaxPlayers: 60, GameType: []string{"battleye", "external"}},
+		{Addr: "10.0.0.2:27015", GamePort: 27015, Appid: 730, Name: "CS2 dust", Map: "de_dust2",
+			OS: "l", Dedicated: true, Players: 20, MaxPlayers: 20, GameType: []string{"secure"}},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	servers := testServers()
+	cases := map[string]int{
+		"":                                      3,
+		`\appid\221100`:                         2,
+		`appid\221100\gametype\battleye`:        2,
+		`\appid\221100\nor\1\gametype\external`: 1,
+		`\nand\2\appid\221100\linux\1`:          2,
+		`\or\2\map\enoch\map\de_dust2`:          2,
+		`\nor\1\and\2\appid\730\full\0`:         2,
+		`\name_match\dayz*`:                     2,
+		`\name_match\*community`:                1,
+		`\gameaddr\10.0.0.1`:                    2,
+		`\gameaddr\10.0.0.1:2402`:               1,
+		`\noplayers\1`:                          1,
+		`\empty\1\full\1`:                       1,
+		`\password\1`:                           0,
+		`\password\0`:                           3,
+		`\gametagsand\battleye,lqs0`:            1,
+		`\gametagsnor\external,secure`:          1,
+		`\steamid\90200000000000001`:            1,
+		`\region\3`:                             1,
+		`\or\2\appid\730\region\3`:              2,
+	}
+
+	for s, want := range cases {
+		f, err := parseFilter(s)
+		if err != nil {
+			t.Errorf("parseFilter(%q): %v", s, err)
+			continue
+		}
+
+		got := 0
+		for i := range servers {
+			if f.match(&servers[i]) {
+				got++
+			}
+		}
+		if got != want {
+			t.Errorf("filter %q matched %d servers, expected %d", s, got, want)
+		}
+	}
+
+	for _, s := range []string{`\appid`, `\nor\2\appid\1`, `\nor\x\appid\1`} {
+		if _, err := parseFilter(s); err == nil {
+			t.Errorf("parseFilter(%q) must fail", s)
+		}
+	}
+}
+
+func TestServerList(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+	srv.AddServers(testServers()...)
+
+	query := serverlist.New(Key)
+	query.SetClient(srv.Client())
+
+	filter := &serverlist.Filter{}
+	filter.Add(serverlist.KeyAppID, "221100")
+	filter.AddNor(serverlist.KeyGameType, "external")
+
+	servers, err := query.Get(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 1 || servers[0].Name != "DayZ Official 1" {
+		t.Fatalf("Get() = %+v, expected one official server", servers)
+	}
+	if len(servers[0].GameType) != 2 || servers[0].GameType[1] != "lqs0" {
+		t.Errorf("GameType = %v, expected [battleye lqs0]", servers[0].GameType)
+	}
+
+	query.SetLimit(1)
+	if servers, _ = query.Get(&serverlist.Filter{}); len(servers) != 1 {
+		t.Errorf("Get() with limit returned %d servers, expected 1", len(servers))
+	}
+
+	query.SetKey("bad")
+	if _, err = query.Get(filter); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Get() with bad key returned %v, expected 403", err)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 3 || reqs[0].Query.Get("filter") != `appid\221100\nor\1\gametype\external` {
+		t.Errorf("Requests() = %+v", reqs)
+	}
+}
+
+func TestGetDetails(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+
+	updated := time.Unix(1700000000, 0)
+	err := srv.LoadFiles(strings.NewReader(fmt.Sprintf(
+		`{"response":{"publishedfiledetails":[{"publishedfileid":"1559212036","consumer_appid":221100,`+
+			`"title":"CF","file_size":"1024","time_updated":%d,"tags":[{"tag":"Mod"}]}]}}`,
+		updated.Unix(),
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := filedetails.New([]uint64{1559212036, 42}, Key)
+	query.SetClient(srv.Client())
+
+	files, err := query.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Get() returned %d files, expected 2", len(files))
+	}
+
+	cf := files[0]
+	if cf.Title != "CF" || cf.FileSize != 1024 || cf.Result != 1 || !cf.TimeUpdated.Equal(updated) {
+		t.Errorf("unexpected file %+v", cf)
+	}
+	if cf.Tags != nil {
+		t.Errorf("tags must be returned only with includetags")
+	}
+	if files[1].PublishedFileID != 42 || files[1].Result != resultFileNotFound {
+		t.Errorf("unknown file = %+v, expected not found result", files[1])
+	}
+}
+
+func TestInject(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+	srv.AddFiles(filedetails.FileDetail{PublishedFileID: 1, Title: "A"})
+	srv.RateLimit(1)
+	srv.FailNext(http.StatusInternalServerError, 1)
+	srv.SetLatency(10 * time.Millisecond)
+
+	query := filedetails.New([]uint64{1}, Key)
+	query.SetClient(srv.Client())
+
+	for _, code := range []string{"429", "500"} {
+		if _, err := query.Get(); err == nil || !strings.Contains(err.Error(), code) {
+			t.Errorf("Get() returned %v, expected %s", err, code)
+		}
+	}
+
+	start := time.Now()
+	if _, err := query.Get(); err != nil {
+		t.Errorf("Get() after injected errors: %v", err)
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("latency is not applied")
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 3 || reqs[0].Status != http.StatusTooManyRequests || reqs[2].Status != http.StatusOK {
+		t.Errorf("Requests() = %+v", reqs)
+	}
+}
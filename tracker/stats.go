@@ -0,0 +1,86 @@
+package tracker
+
+import "time"
+
+// Samples is the history of a server ordered by time.
+type Samples []Sample
+
+// HourStats is the population of a server in an hour of day.
+type HourStats struct {
+	Average float64 // Average number of players
+	Peak    uint16  // Maximum number of players
+	Samples int     // Number of samples in the hour, zero if the server was never seen at this hour
+}
+
+// MapPeriod is a period of time a map was running on a server.
+type MapPeriod struct {
+	Start time.Time // Time of the first sample with the map
+	End   time.Time // Time of the last sample with the map
+	Map   string    // Map name
+}
+
+// Hourly returns peak and average players for every hour of day in the location,
+// a nil location is UTC. The result can be used to find the best time to play.
+func (s Samples) Hourly(loc *time.Location) [24]HourStats {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var stats [24]HourStats
+	var sums [24]int
+	for _, sample := range s {
+		h := sample.Time.In(loc).Hour()
+		stats[h].Samples++
+		stats[h].Peak = max(stats[h].Peak, sample.Players)
+		sums[h] += int(sample.Players)
+	}
+
+	for h := range stats {
+		if stats[h].Samples > 0 {
+			stats[h].Average = float64(sums[h]) / float64(stats[h].Samples)
+		}
+	}
+
+	return stats
+}
+
+// Peak returns the sample with the most players, the earliest one if several samples have the same number.
+// The zero Sample is returned for empty history.
+func (s Samples) Peak() Sample {
+	var peak Sample
+	for i, sample := range s {
+		if i == 0 || sample.Players > peak.Players {
+			peak = sample
+		}
+	}
+
+	return peak
+}
+
+// Average returns the average number of players.
+func (s Samples) Average() float64 {
+	if len(s) == 0 {
+		return 0
+	}
+
+	sum := 0
+	for _, sample := range s {
+		sum += int(sample.Players)
+	}
+
+	return float64(sum) / float64(len(s))
+}
+
+// Maps returns the map rotation history, consecutive samples with the same map are joined into one period.
+func (s Samples) Maps() []MapPeriod {
+	var periods []MapPeriod
+	for _, sample := range s {
+		if n := len(periods); n > 0 && periods[n-1].Map == sample.Map {
+			periods[n-1].End = sample.Time
+			continue
+		}
+		periods = append(periods, MapPeriod{Map: sample.Map, Start: sample.Time, End: sample.Time})
+	}
+
+	return periods
+}
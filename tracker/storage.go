@@ -0,0 +1,311 @@
+package tracker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	json "github.com/json-iterator/go"
+)
+
+// Sample is the state of a server at the time of a poll.
+type Sample struct {
+	Time       time.Time `json:"time"`        // Time of the poll
+	Map        string    `json:"map"`         // Current map
+	Version    string    `json:"version"`     // Game version
+	SteamID    uint64    `json:"steamid"`     // Server SteamID
+	Players    uint16    `json:"players"`     // Number of players
+	MaxPlayers uint16    `json:"max_players"` // Maximum number of players
+}
+
+// Storage keeps polls and samples of servers, implementations must be safe for concurrent use.
+type Storage interface {
+	// Record stores samples of a poll made at the time, a poll without samples is recorded too.
+	Record(t time.Time, samples []Sample) error
+	// Samples returns samples of the server in the [from, to) interval ordered by time.
+	Samples(steamID uint64, from, to time.Time) (Samples, error)
+	// Polls returns times of polls in the [from, to) interval ordered by time.
+	Polls(from, to time.Time) ([]time.Time, error)
+	// Servers returns SteamIDs of all recorded servers.
+	Servers() ([]uint64, error)
+}
+
+// Pruner is implemented by storages able to remove old polls and samples,
+// it is used by Tracker.SetRetention.
+type Pruner interface {
+	// Prune removes polls and samples recorded before the time.
+	Prune(before time.Time) error
+}
+
+// MemoryStorage keeps samples in memory. A sample takes about 100 bytes plus the map and version
+// strings, e.g. 1000 servers polled every 5 minutes take about 30 MB per day,
+// use Prune or Tracker.SetRetention to limit the history.
+type MemoryStorage struct {
+	samples map[uint64]Samples
+	polls   []time.Time
+	mu      sync.RWMutex
+}
+
+// NewMemoryStorage creates a new empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{samples: make(map[uint64]Samples)}
+}
+
+// Record implements Storage.
+func (m *MemoryStorage) Record(t time.Time, samples []Sample) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.polls = insertTime(m.polls, t)
+	for _, s := range samples {
+		history := m.samples[s.SteamID]
+		i := sort.Search(len(history), func(i int) bool { return history[i].Time.After(s.Time) })
+		m.samples[s.SteamID] = append(history[:i], append(Samples{s}, history[i:]...)...)
+	}
+
+	return nil
+}
+
+// Samples implements Storage.
+func (m *MemoryStorage) Samples(steamID uint64, from, to time.Time) (Samples, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	history := m.samples[steamID]
+	start, end := timeRange(len(history), func(i int) time.Time { return history[i].Time }, from, to)
+	return append(Samples(nil), history[start:end]...), nil
+}
+
+// Polls implements Storage.
+func (m *MemoryStorage) Polls(from, to time.Time) ([]time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	start, end := timeRange(len(m.polls), func(i int) time.Time { return m.polls[i] }, from, to)
+	return append([]time.Time(nil), m.polls[start:end]...), nil
+}
+
+// Prune implements Pruner.
+func (m *MemoryStorage) Prune(before time.Time) error {
+	m.prune(before)
+	return nil
+}
+
+// prune removes polls and samples before the time and reports whether anything was removed,
+// remaining items are copied to release the memory of removed ones
+func (m *MemoryStorage) prune(before time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pruned := false
+	if i, _ := timeRange(len(m.polls), func(i int) time.Time { return m.polls[i] }, before, time.Time{}); i > 0 {
+		m.polls = append([]time.Time(nil), m.polls[i:]...)
+		pruned = true
+	}
+
+	for id, history := range m.samples {
+		i, _ := timeRange(len(history), func(i int) time.Time { return history[i].Time }, before, time.Time{})
+		switch {
+		case i == len(history):
+			delete(m.samples, id)
+		case i > 0:
+			m.samples[id] = append(Samples(nil), history[i:]...)
+		default:
+			continue
+		}
+		pruned = true
+	}
+
+	return pruned
+}
+
+// Servers implements Storage.
+func (m *MemoryStorage) Servers() ([]uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]uint64, 0, len(m.samples))
+	for id := range m.samples {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+// FileStorage keeps samples in memory and appends every poll to a file as a line of JSON (NDJSON),
+// the file is loaded back when opened. All samples of the file are kept in memory as in MemoryStorage,
+// Prune removes them from memory and rewrites the file.
+type FileStorage struct {
+	*MemoryStorage
+	file *os.File
+	mu   sync.Mutex
+}
+
+// poll is a line of the FileStorage file
+type poll struct {
+	Time    time.Time `json:"time"`
+	Samples []Sample  `json:"samples,omitempty"`
+}
+
+// OpenFileStorage opens or creates the file and loads recorded polls.
+func OpenFileStorage(path string) (*FileStorage, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := &FileStorage{MemoryStorage: NewMemoryStorage(), file: file}
+	if err := fs.load(); err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+
+	return fs, nil
+}
+
+// Record implements Storage, the poll is written to the file before it is kept in memory.
+func (fs *FileStorage) Record(t time.Time, samples []Sample) error {
+	line, err := json.Marshal(poll{Time: t, Samples: samples})
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, err := fs.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return fs.MemoryStorage.Record(t, samples)
+}
+
+// Prune implements Pruner, the file is rewritten without polls made before the time.
+func (fs *FileStorage) Prune(before time.Time) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if !fs.MemoryStorage.prune(before) {
+		return nil
+	}
+
+	name := fs.file.Name()
+	info, err := fs.file.Stat()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := fs.rewrite(tmp, before); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	old := fs.file
+	fs.file = file
+
+	return old.Close()
+}
+
+// rewrite copies polls of the file made since the time to w
+func (fs *FileStorage) rewrite(w io.Writer, since time.Time) error {
+	if _, err := fs.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	err := fs.scan(func(p poll) error {
+		if p.Time.Before(since) {
+			return nil
+		}
+
+		line, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		_, err = bw.Write(append(line, '\n'))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// Close closes the file.
+func (fs *FileStorage) Close() error {
+	return fs.file.Close()
+}
+
+// load reads polls from the file
+func (fs *FileStorage) load() error {
+	return fs.scan(func(p poll) error {
+		return fs.MemoryStorage.Record(p.Time, p.Samples)
+	})
+}
+
+// scan decodes polls from the current position of the file
+func (fs *FileStorage) scan(fn func(p poll) error) error {
+	scanner := bufio.NewScanner(fs.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var p poll
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			return fmt.Errorf("failed to decode line %d of %s: %w", n, fs.file.Name(), err)
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// insertTime inserts the time keeping the slice sorted
+func insertTime(times []time.Time, t time.Time) []time.Time {
+	i := sort.Search(len(times), func(i int) bool { return times[i].After(t) })
+	return append(times[:i], append([]time.Time{t}, times[i:]...)...)
+}
+
+// timeRange returns bounds of sorted items in the [from, to) interval, zero times are not limiting
+func timeRange(n int, at func(i int) time.Time, from, to time.Time) (int, int) {
+	start, end := 0, n
+	if !from.IsZero() {
+		start = sort.Search(n, func(i int) bool { return !at(i).Before(from) })
+	}
+	if !to.IsZero() {
+		end = sort.Search(n, func(i int) bool { return !at(i).Before(to) })
+	}
+	if end < start {
+		end = start
+	}
+
+	return start, end
+}
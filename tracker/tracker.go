@@ -0,0 +1,275 @@
+/*
+Package tracker records the population history of game servers.
+
+Tracker polls a serverlist.Lister (e.g. serverlist.SteamQuery) on an interval and records
+a sample per server keyed by SteamID: players, max players, map and version.
+Servers without SteamID, such as those of serverlist.MasterQuery, can not be tracked.
+DayZ and Arma servers get a new SteamID on every restart, a server listed with a new SteamID
+at the address of a tracked server not listed under its own SteamID keeps the tracked SteamID.
+Samples are kept in a pluggable Storage, MemoryStorage and FileStorage are provided.
+The recorded history answers questions like "best time to play" with peak and average
+players per hour of day, uptime of servers and map rotation history.
+
+# Example usage:
+
+	package main
+
+	import (
+		"context"
+		"fmt"
+		"log"
+		"os"
+		"time"
+
+		"github.com/woozymasta/steam/serverlist"
+		"github.com/woozymasta/steam/tracker"
+		"github.com/woozymasta/steam/utils/appid"
+	)
+
+	func main() {
+		storage, err := tracker.OpenFileStorage("population.ndjson")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer storage.Close()
+
+		filter := (&serverlist.Filter{}).AppID(appid.DayZ)
+		t := tracker.New(serverlist.New(os.Getenv("STEAM_API_KEY")), filter, storage)
+		t.SetInterval(5 * time.Minute)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 24*time.Hour)
+		defer cancel()
+		if err := t.Run(ctx); err != nil {
+			log.Fatal(err)
+		}
+
+		history, err := t.History(90200000000000001, time.Now().Add(-24*time.Hour), time.Now())
+		if err != nil {
+			log.Fatal(err)
+		}
+		for hour, stats := range history.Hourly(time.Local) {
+			fmt.Printf("%02d:00 peak %d average %.1f\n", hour, stats.Peak, stats.Average)
+		}
+	}
+*/
+package tracker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/utils/webapi"
+)
+
+// DefaultInterval defines the default interval between polls of the server list.
+const DefaultInterval = 5 * time.Minute
+
+// Tracker polls the server list and records samples of servers to the storage.
+type Tracker struct {
+	lister    serverlist.Lister
+	filter    *serverlist.Filter
+	storage   Storage
+	logger    *slog.Logger
+	links     map[uint64]uint64 // tracked SteamIDs by SteamIDs of the last poll
+	addrs     map[string]uint64 // tracked SteamIDs by addresses
+	interval  time.Duration
+	retention time.Duration
+	mu        sync.Mutex
+}
+
+// New creates a new Tracker polling the lister with the filter and recording samples to the storage.
+// A nil storage is replaced with a new MemoryStorage.
+func New(lister serverlist.Lister, filter *serverlist.Filter, storage Storage) *Tracker {
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+
+	return &Tracker{
+		lister:   lister,
+		filter:   filter,
+		storage:  storage,
+		links:    make(map[uint64]uint64),
+		addrs:    make(map[string]uint64),
+		interval: DefaultInterval,
+	}
+}
+
+// SetInterval sets the interval between polls of Run.
+func (t *Tracker) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	t.interval = interval
+}
+
+// SetRetention sets how long the history is kept, zero keeps it forever (default).
+// After every poll polls and samples older than the retention are removed if the storage implements Pruner,
+// the cutoff is truncated to an hour, so FileStorage rewrites the file at most once an hour.
+func (t *Tracker) SetRetention(retention time.Duration) {
+	t.retention = max(retention, 0)
+}
+
+// SetLogger sets the logger for diagnostic messages, nothing is logged by default.
+func (t *Tracker) SetLogger(logger *slog.Logger) {
+	t.logger = logger
+}
+
+// Storage returns the storage of samples.
+func (t *Tracker) Storage() Storage {
+	return t.storage
+}
+
+// Run polls the server list immediately and then on every interval until the context is done.
+// Failed polls are logged and do not stop the tracker, storage errors are returned.
+func (t *Tracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var storageErr *StorageError
+			if errors.As(err, &storageErr) {
+				return err
+			}
+			webapi.Logger(t.logger).Warn("failed to poll server list", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll requests the server list once and records a sample of every server with SteamID.
+// Servers without SteamID can not be tracked and are skipped, a server listed twice is recorded once.
+// A server with a new SteamID at the address of a tracked server, which is not listed under its own
+// SteamID, is recorded with the tracked SteamID. Links of addresses are kept in memory of the Tracker.
+func (t *Tracker) Poll(ctx context.Context) error {
+	servers, err := t.lister.GetContext(ctx, t.filter)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	samples := t.samples(now, servers)
+
+	if err := t.storage.Record(now, samples); err != nil {
+		return &StorageError{Err: err}
+	}
+	if pruner, ok := t.storage.(Pruner); ok && t.retention > 0 {
+		if err := pruner.Prune(now.Add(-t.retention).Truncate(time.Hour)); err != nil {
+			return &StorageError{Err: err}
+		}
+	}
+
+	webapi.Logger(t.logger).Debug("server list polled", "servers", len(servers), "samples", len(samples))
+	return nil
+}
+
+// samples returns samples of servers with SteamID keyed by tracked SteamIDs and updates the links
+func (t *Tracker) samples(now time.Time, servers serverlist.Servers) []Sample {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// tracked SteamIDs listed under their own or a linked SteamID are not re-linked by address
+	listed := make(map[uint64]struct{}, len(servers))
+	for i := range servers {
+		if id := servers[i].SteamID; id != 0 {
+			if tracked, ok := t.links[id]; ok {
+				id = tracked
+			}
+			listed[id] = struct{}{}
+		}
+	}
+
+	samples := make([]Sample, 0, len(servers))
+	links := make(map[uint64]uint64, len(servers))
+	for i := range servers {
+		s := &servers[i]
+		if s.SteamID == 0 {
+			continue
+		}
+		if _, ok := links[s.SteamID]; ok {
+			continue
+		}
+
+		id, ok := t.links[s.SteamID]
+		if !ok {
+			id = s.SteamID
+			if tracked, ok := t.addrs[s.Addr]; ok && s.Addr != "" {
+				if _, ok := listed[tracked]; !ok {
+					id = tracked
+					listed[tracked] = struct{}{}
+				}
+			}
+		}
+		links[s.SteamID] = id
+		if s.Addr != "" {
+			t.addrs[s.Addr] = id
+		}
+
+		samples = append(samples, Sample{
+			Time:       now,
+			Map:        s.Map,
+			Version:    s.Version,
+			SteamID:    id,
+			Players:    s.Players,
+			MaxPlayers: s.MaxPlayers,
+		})
+	}
+	t.links = links
+
+	return samples
+}
+
+// History returns samples of the server recorded in the [from, to) interval.
+func (t *Tracker) History(steamID uint64, from, to time.Time) (Samples, error) {
+	return t.storage.Samples(steamID, from, to)
+}
+
+// Uptime returns the share (0..1) of polls in the [from, to) interval in which the server was listed,
+// samples are counted once per poll time.
+func (t *Tracker) Uptime(steamID uint64, from, to time.Time) (float64, error) {
+	polls, err := t.storage.Polls(from, to)
+	if err != nil || len(polls) == 0 {
+		return 0, err
+	}
+
+	samples, err := t.storage.Samples(steamID, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	listed := 0
+	for i := range samples {
+		if i == 0 || !samples[i].Time.Equal(samples[i-1].Time) {
+			listed++
+		}
+	}
+
+	return float64(min(listed, len(polls))) / float64(len(polls)), nil
+}
+
+// StorageError is returned by Poll when samples can not be recorded or pruned.
+type StorageError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e *StorageError) Error() string {
+	return "failed to record samples: " + e.Err.Error()
+}
+
+// Unwrap returns the error of the storage.
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
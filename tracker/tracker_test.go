@@ -0,0 +1,297 @@
+package tracker_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/woozymasta/steam/serverlist"
+	"github.com/woozymasta/steam/tracker"
+)
+
+// lister returns prepared server lists one by one
+type lister struct {
+	polls []serverlist.Servers
+	err   error
+}
+
+func (l *lister) Get(filter *serverlist.Filter) (serverlist.Servers, error) {
+	return l.GetContext(context.Background(), filter)
+}
+
+func (l *lister) GetContext(context.Context, *serverlist.Filter) (serverlist.Servers, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	if len(l.polls) == 0 {
+		return nil, nil
+	}
+
+	servers := l.polls[0]
+	l.polls = l.polls[1:]
+	return servers, nil
+}
+
+// day is the date of recorded test samples
+var day = time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)
+
+// record stores polls every hour from midnight, players are -1 for polls without the server
+func record(t *testing.T, storage tracker.Storage, players []int, maps []string) {
+	t.Helper()
+
+	for i, p := range players {
+		var samples []tracker.Sample
+		if p >= 0 {
+			samples = append(samples, tracker.Sample{
+				Time: day.Add(time.Duration(i) * time.Hour), SteamID: 1, Players: uint16(p), MaxPlayers: 60, Map: maps[i],
+			})
+		}
+		if err := storage.Record(day.Add(time.Duration(i)*time.Hour), samples); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPoll(t *testing.T) {
+	l := &lister{polls: []serverlist.Servers{
+		{{SteamID: 1, Players: 5, MaxPlayers: 60, Map: "chernarusplus", Version: "1.26"}, {Addr: "10.0.0.1:27016"}},
+		{{SteamID: 1, Players: 7, MaxPlayers: 60, Map: "enoch", Version: "1.26"}, {SteamID: 2, Players: 1}, {SteamID: 1}},
+	}}
+	tr := tracker.New(l, &serverlist.Filter{}, nil)
+
+	for range l.polls {
+		if err := tr.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids, _ := tr.Storage().Servers()
+	if !reflect.DeepEqual(ids, []uint64{1, 2}) {
+		t.Errorf("Servers() = %v", ids)
+	}
+
+	history, err := tr.History(1, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Players != 5 || history[1].Map != "enoch" || history[1].Version != "1.26" {
+		t.Errorf("History() = %+v", history)
+	}
+
+	if uptime, _ := tr.Uptime(2, time.Time{}, time.Time{}); uptime != 0.5 {
+		t.Errorf("Uptime() = %v, expected 0.5", uptime)
+	}
+	if uptime, _ := tr.Uptime(1, time.Time{}, time.Time{}); uptime != 1 {
+		t.Errorf("Uptime() of server listed twice in a poll = %v, expected 1", uptime)
+	}
+
+	// Duplicate samples of a poll recorded by another writer are counted once
+	now := time.Now()
+	if err := tr.Storage().Record(now, []tracker.Sample{{Time: now, SteamID: 3}, {Time: now, SteamID: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if uptime, _ := tr.Uptime(3, now, now.Add(time.Second)); uptime != 1 {
+		t.Errorf("Uptime() with duplicate samples = %v, expected 1", uptime)
+	}
+
+	l.err = errors.New("unavailable")
+	if err := tr.Poll(context.Background()); err == nil {
+		t.Error("Poll() did not return the error of the lister")
+	}
+}
+
+func TestPollRestart(t *testing.T) {
+	a, b, c := "10.0.0.1:2302", "10.0.0.2:2302", "10.0.0.3:2302"
+	l := &lister{polls: []serverlist.Servers{
+		{{SteamID: 1, Addr: a, Players: 5}, {SteamID: 2, Addr: b}},
+		// server a restarted with a new SteamID
+		{{SteamID: 11, Addr: a, Players: 6}, {SteamID: 2, Addr: b}},
+		// server a is down
+		{{SteamID: 2, Addr: b}},
+		// server a is back with another SteamID, a new server c and a server sharing the address of listed b
+		{{SteamID: 21, Addr: a, Players: 7}, {SteamID: 2, Addr: b}, {SteamID: 3, Addr: c}, {SteamID: 31, Addr: b}},
+	}}
+	tr := tracker.New(l, &serverlist.Filter{}, nil)
+
+	for range l.polls {
+		if err := tr.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids, _ := tr.Storage().Servers()
+	if !reflect.DeepEqual(ids, []uint64{1, 2, 3, 31}) {
+		t.Errorf("Servers() = %v, expected 1, 2, 3 and 31", ids)
+	}
+
+	history, _ := tr.History(1, time.Time{}, time.Time{})
+	if len(history) != 3 || history[1].Players != 6 || history[2].Players != 7 {
+		t.Errorf("History() of restarted server = %+v", history)
+	}
+	if history, _ := tr.History(2, time.Time{}, time.Time{}); len(history) != 4 {
+		t.Errorf("History() of server listed under own SteamID = %d samples, expected 4", len(history))
+	}
+}
+
+func TestPollWithoutSteamID(t *testing.T) {
+	// serverlist.MasterQuery lists only addresses
+	l := &lister{polls: []serverlist.Servers{{{Addr: "10.0.0.1:2302"}, {Addr: "10.0.0.2:2302"}}}}
+	tr := tracker.New(l, &serverlist.Filter{}, nil)
+
+	if err := tr.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, _ := tr.Storage().Servers(); len(ids) != 0 {
+		t.Errorf("Servers() = %v, servers without SteamID are recorded", ids)
+	}
+	if polls, _ := tr.Storage().Polls(time.Time{}, time.Now().Add(time.Second)); len(polls) != 1 {
+		t.Errorf("Polls() = %v, expected the poll without samples", polls)
+	}
+}
+
+func TestRun(t *testing.T) {
+	l := &lister{polls: []serverlist.Servers{{{SteamID: 1}}, {{SteamID: 1}}, {{SteamID: 1}}}}
+	tr := tracker.New(l, &serverlist.Filter{}, nil)
+	tr.SetInterval(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := tr.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if history, _ := tr.History(1, time.Time{}, time.Time{}); len(history) != 3 {
+		t.Errorf("Run() recorded %d samples, expected 3", len(history))
+	}
+}
+
+func TestStats(t *testing.T) {
+	storage := tracker.NewMemoryStorage()
+	record(t, storage,
+		[]int{2, 4, -1, 10, 30, 30, -1, 6},
+		[]string{"a", "a", "", "a", "b", "b", "", "a"},
+	)
+	tr := tracker.New(&lister{}, nil, storage)
+
+	history, _ := tr.History(1, day.Add(time.Hour), day.Add(7*time.Hour))
+	if len(history) != 4 || history[0].Players != 4 || history[3].Players != 30 {
+		t.Errorf("History() = %+v", history)
+	}
+
+	all, _ := tr.History(1, time.Time{}, time.Time{})
+	if peak := all.Peak(); peak.Players != 30 || !peak.Time.Equal(day.Add(4*time.Hour)) {
+		t.Errorf("Peak() = %+v", peak)
+	}
+	if avg := all.Average(); avg != 82.0/6 {
+		t.Errorf("Average() = %v", avg)
+	}
+
+	hourly := all.Hourly(time.FixedZone("UTC+3", 3*3600))
+	if hourly[7].Peak != 30 || hourly[7].Average != 30 || hourly[3].Average != 2 || hourly[5].Samples != 0 {
+		t.Errorf("Hourly() = %+v", hourly)
+	}
+
+	maps := all.Maps()
+	expected := []string{"a", "b", "a"}
+	if len(maps) != 3 || !maps[0].End.Equal(day.Add(3*time.Hour)) || !maps[1].Start.Equal(day.Add(4*time.Hour)) {
+		t.Errorf("Maps() = %+v", maps)
+	}
+	for i := range maps {
+		if maps[i].Map != expected[i] {
+			t.Errorf("Maps()[%d] = %s, expected %s", i, maps[i].Map, expected[i])
+		}
+	}
+
+	if uptime, _ := tr.Uptime(1, time.Time{}, time.Time{}); uptime != 0.75 {
+		t.Errorf("Uptime() = %v, expected 0.75", uptime)
+	}
+}
+
+func TestFileStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "population.ndjson")
+
+	storage, err := tracker.OpenFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record(t, storage, []int{1, -1, 3}, []string{"a", "", "b"})
+	if err := storage.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	storage, err = tracker.OpenFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = storage.Close() }()
+
+	polls, _ := storage.Polls(time.Time{}, time.Time{})
+	samples, _ := storage.Samples(1, time.Time{}, time.Time{})
+	if len(polls) != 3 || len(samples) != 2 || samples[1].Map != "b" || !samples[1].Time.Equal(day.Add(2*time.Hour)) {
+		t.Errorf("loaded %d polls and samples %+v", len(polls), samples)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "population.ndjson")
+
+	storage, err := tracker.OpenFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = storage.Close() }()
+	record(t, storage, []int{1, 2, -1, 4}, []string{"a", "a", "", "b"})
+
+	if err := storage.Prune(day.Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Record(day.Add(4*time.Hour), []tracker.Sample{{Time: day.Add(4 * time.Hour), SteamID: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(s tracker.Storage, name string) {
+		polls, _ := s.Polls(time.Time{}, time.Time{})
+		samples, _ := s.Samples(1, time.Time{}, time.Time{})
+		ids, _ := s.Servers()
+		if len(polls) != 3 || len(samples) != 1 || samples[0].Map != "b" || !reflect.DeepEqual(ids, []uint64{1, 2}) {
+			t.Errorf("%s: %d polls, samples %+v, servers %v after Prune()", name, len(polls), samples, ids)
+		}
+	}
+	check(storage, "pruned")
+
+	reopened, err := tracker.OpenFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reopened.Close() }()
+	check(reopened, "reopened")
+
+	memory := tracker.NewMemoryStorage()
+	record(t, memory, []int{1, 2}, []string{"a", "a"})
+	if err := memory.Prune(day.Add(24 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := memory.Servers(); len(ids) != 0 {
+		t.Errorf("Servers() = %v after Prune() of all samples", ids)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	storage := tracker.NewMemoryStorage()
+	record(t, storage, []int{1}, []string{"a"})
+
+	l := &lister{polls: []serverlist.Servers{{{SteamID: 1}}}}
+	tr := tracker.New(l, &serverlist.Filter{}, storage)
+	tr.SetRetention(24 * time.Hour)
+	if err := tr.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if history, _ := tr.History(1, time.Time{}, time.Time{}); len(history) != 1 || !history[0].Time.After(day) {
+		t.Errorf("History() = %+v, expected the last poll only", history)
+	}
+}
@@ -0,0 +1,192 @@
+package webapi
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DailyLimit is the Steam Web API limit of calls per API key per day.
+	DailyLimit = 100000
+
+	// DefaultBenchTime is the time a key is not used after 403 Forbidden or 429 Too Many Requests.
+	DefaultBenchTime = 10 * time.Minute
+)
+
+// ErrNoKeys is returned by KeyPool when all keys are benched or exhausted the daily limit.
+var ErrNoKeys = errors.New("no Steam API keys available")
+
+// KeyProvider provides Steam Web API keys for requests.
+// Implementations must be safe for concurrent use.
+type KeyProvider interface {
+	// Key returns the API key for the next request.
+	Key() (string, error)
+	// Report reports the HTTP status of a request made with the key, 0 if no response was received.
+	Report(key string, status int)
+}
+
+// StaticKey is a KeyProvider always returning the same key.
+type StaticKey string
+
+// Key returns the key.
+func (k StaticKey) Key() (string, error) {
+	return string(k), nil
+}
+
+// Report does nothing.
+func (StaticKey) Report(string, int) {}
+
+// KeyUsage is the usage statistic of a key in KeyPool.
+type KeyUsage struct {
+	BenchedUntil time.Time // Time until the key is not used, zero if the key is active
+	Key          string    // API key, redacted
+	Calls        int       // Count of calls made with the key today (UTC)
+}
+
+// KeyPool is a KeyProvider rotating keys in round-robin order.
+// It counts calls per key against the daily limit and benches keys that get 403 or 429 responses.
+type KeyPool struct {
+	now   func() time.Time
+	keys  []*keyState
+	bench time.Duration
+	limit int
+	next  int
+	mu    sync.Mutex
+}
+
+// keyState is a key with its usage
+type keyState struct {
+	day          time.Time
+	benchedUntil time.Time
+	key          string
+	calls        int
+}
+
+// NewKeyPool creates a new KeyPool with DailyLimit and DefaultBenchTime, empty and duplicate keys are ignored.
+func NewKeyPool(keys ...string) *KeyPool {
+	p := &KeyPool{
+		now:   time.Now,
+		bench: DefaultBenchTime,
+		limit: DailyLimit,
+	}
+
+	seen := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		if _, ok := seen[k]; ok || k == "" {
+			continue
+		}
+		seen[k] = struct{}{}
+		p.keys = append(p.keys, &keyState{key: k})
+	}
+
+	return p
+}
+
+// SetDailyLimit sets the maximum count of calls per key per day (UTC), 0 disables the limit.
+func (p *KeyPool) SetDailyLimit(limit int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limit = limit
+}
+
+// SetBenchTime sets the time a key is not used after 403 or 429 response.
+func (p *KeyPool) SetBenchTime(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bench = d
+}
+
+// Len returns the count of keys in the pool.
+func (p *KeyPool) Len() int {
+	return len(p.keys)
+}
+
+// Key returns the next available key and counts the call, ErrNoKeys if no key is available.
+func (p *KeyPool) Key() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	for i := 0; i < len(p.keys); i++ {
+		k := p.keys[(p.next+i)%len(p.keys)]
+
+		if k.day.Before(today) {
+			k.day = today
+			k.calls = 0
+		}
+		if now.Before(k.benchedUntil) || (p.limit > 0 && k.calls >= p.limit) {
+			continue
+		}
+
+		p.next = (p.next + i + 1) % len(p.keys)
+		k.calls++
+		return k.key, nil
+	}
+
+	return "", ErrNoKeys
+}
+
+// Report benches the key on 403 Forbidden and 429 Too Many Requests responses.
+func (p *KeyPool) Report(key string, status int) {
+	if status != http.StatusForbidden && status != http.StatusTooManyRequests {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, k := range p.keys {
+		if k.key == key {
+			k.benchedUntil = p.now().Add(p.bench)
+			return
+		}
+	}
+}
+
+// Usage returns usage statistics of all keys in the pool order.
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	today := now.UTC().Truncate(24 * time.Hour)
+	usage := make([]KeyUsage, len(p.keys))
+	for i, k := range p.keys {
+		usage[i] = KeyUsage{Key: RedactKey(k.key)}
+		if !k.day.Before(today) {
+			usage[i].Calls = k.calls
+		}
+		if now.Before(k.benchedUntil) {
+			usage[i].BenchedUntil = k.benchedUntil
+		}
+	}
+
+	return usage
+}
+
+// Attempts returns the count of attempts to make with keys of the provider:
+// one per key for providers with Len method (e.g. KeyPool), one otherwise.
+func Attempts(p KeyProvider) int {
+	if l, ok := p.(interface{ Len() int }); ok && l.Len() > 1 {
+		return l.Len()
+	}
+
+	return 1
+}
+
+// Retryable reports whether the request should be retried with another key.
+func Retryable(status int) bool {
+	return status == http.StatusForbidden || status == http.StatusTooManyRequests
+}
+
+// RedactKey masks all but the last 4 characters of the key.
+func RedactKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+
+	return "****" + key[len(key)-4:]
+}
@@ -0,0 +1,71 @@
+package webapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestKeyPool(t *testing.T) {
+	now := time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)
+	p := NewKeyPool("aaaa1111", "bbbb2222", "", "aaaa1111")
+	p.now = func() time.Time { return now }
+	p.SetDailyLimit(2)
+	p.SetBenchTime(time.Minute)
+
+	if p.Len() != 2 {
+		t.Fatalf("Len() = %d, expected 2", p.Len())
+	}
+
+	// Round robin
+	var got []string
+	for i := 0; i < 4; i++ {
+		k, err := p.Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, k)
+	}
+	if got[0] != "aaaa1111" || got[1] != "bbbb2222" || got[2] != "aaaa1111" || got[3] != "bbbb2222" {
+		t.Errorf("Key() order %v", got)
+	}
+
+	// Daily limit
+	if _, err := p.Key(); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("Key() over limit returned %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := p.Key(); err != nil {
+		t.Errorf("Key() on the next day returned %v", err)
+	}
+
+	// Bench
+	p.SetDailyLimit(0)
+	p.Report("aaaa1111", http.StatusTooManyRequests)
+	p.Report("bbbb2222", http.StatusInternalServerError)
+	for i := 0; i < 3; i++ {
+		if k, _ := p.Key(); k != "bbbb2222" {
+			t.Errorf("Key() returned benched key %s", k)
+		}
+	}
+
+	usage := p.Usage()
+	if usage[0].Key != "****1111" || usage[0].BenchedUntil.IsZero() || usage[1].Calls != 3 {
+		t.Errorf("Usage() = %+v", usage)
+	}
+
+	now = now.Add(time.Minute)
+	if k, _ := p.Key(); k != "aaaa1111" {
+		t.Errorf("Key() after bench returned %s", k)
+	}
+}
+
+func TestAttempts(t *testing.T) {
+	if n := Attempts(StaticKey("a")); n != 1 {
+		t.Errorf("Attempts(StaticKey) = %d", n)
+	}
+	if n := Attempts(NewKeyPool("a", "b", "c")); n != 3 {
+		t.Errorf("Attempts(KeyPool) = %d", n)
+	}
+}
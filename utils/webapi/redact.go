@@ -0,0 +1,78 @@
+package webapi
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// credentialParams are query parameters with credentials masked by RedactURL
+var credentialParams = []string{"key", "access_token"}
+
+// credentialRe matches credentials in URLs that can't be parsed
+var credentialRe = regexp.MustCompile(`((?:^|[?&])(?:key|access_token)=)[^&\s"]*`)
+
+// RedactURL masks API keys and access tokens in the query string of the URL,
+// other parts of the URL are kept as is.
+func RedactURL(rawURL string) string {
+	start := strings.IndexByte(rawURL, '?')
+	if start < 0 {
+		return rawURL
+	}
+	end := len(rawURL)
+	if i := strings.IndexByte(rawURL[start:], '#'); i >= 0 {
+		end = start + i
+	}
+
+	params := strings.Split(rawURL[start+1:end], "&")
+	changed := false
+	for i, param := range params {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok || value == "" || !slices.Contains(credentialParams, name) {
+			continue
+		}
+
+		masked := "****"
+		if v, err := url.QueryUnescape(value); err == nil {
+			masked = RedactKey(v)
+		}
+		params[i] = name + "=" + masked
+		changed = true
+	}
+	if !changed {
+		return rawURL
+	}
+
+	return rawURL[:start+1] + strings.Join(params, "&") + rawURL[end:]
+}
+
+// RedactError masks credentials in the URL of *url.Error returned by http.Client,
+// other errors are returned as is.
+func RedactError(err error) error {
+	var uerr *url.Error
+	if !errors.As(err, &uerr) {
+		return err
+	}
+	if uerr.URL == RedactURL(uerr.URL) {
+		return err
+	}
+
+	redacted := &url.Error{Op: uerr.Op, URL: RedactURL(uerr.URL), Err: uerr.Err}
+	if err == error(uerr) {
+		return redacted
+	}
+
+	// The url.Error is wrapped, keep the message of the wrapper with the redacted URL
+	return &redactedError{msg: credentialRe.ReplaceAllString(err.Error(), "${1}****"), err: redacted}
+}
+
+// redactedError is an error with the redacted message wrapping the redacted *url.Error
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
@@ -0,0 +1,88 @@
+/*
+Package webapi provides common plumbing shared by the Steam Web API clients of this module:
+request observers for metrics, helpers for structured logging with log/slog
+and API key providers with rotation and quota accounting.
+
+# Example usage:
+
+	query := filedetails.New(ids, key)
+	query.SetLogger(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+	query.SetObserver(webapi.ObserverFunc(func(r webapi.RequestInfo) {
+		requestsTotal.WithLabelValues(r.Endpoint, strconv.Itoa(r.Status)).Inc()
+		requestDuration.WithLabelValues(r.Endpoint).Observe(r.Latency.Seconds())
+	}))
+*/
+package webapi
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// RequestInfo describes a finished HTTP request to the Steam Web API.
+type RequestInfo struct {
+	Err       error         // Request error, nil if the request succeeded
+	Endpoint  string        // Endpoint URL without query string
+	Latency   time.Duration // Time from sending the request to reading the whole response body
+	Bytes     int64         // Count of response body bytes read
+	ChunkSize int           // Count of requested items (file IDs for GetDetails, limit for GetServerList)
+	Status    int           // HTTP status code, 0 if no response was received
+	Retries   int           // Count of retries made before this request
+}
+
+// Observer is called after every HTTP request made by the API clients.
+// Implementations must be safe for concurrent use.
+type Observer interface {
+	ObserveRequest(info RequestInfo)
+}
+
+// ObserverFunc is an adapter to use ordinary functions as Observer.
+type ObserverFunc func(info RequestInfo)
+
+// ObserveRequest calls f(info).
+func (f ObserverFunc) ObserveRequest(info RequestInfo) {
+	f(info)
+}
+
+// Observe calls the observer if it is not nil.
+func Observe(o Observer, info RequestInfo) {
+	if o != nil {
+		o.ObserveRequest(info)
+	}
+}
+
+// Logger returns l or a logger discarding all records if l is nil.
+// API clients use it to stay silent unless a logger is configured.
+func Logger(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return discard
+	}
+
+	return l
+}
+
+// discard is a logger with the disabled handler
+var discard = slog.New(discardHandler{})
+
+// discardHandler is a slog.Handler discarding all records
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// CountingReader counts bytes read from the underlying reader, used to fill RequestInfo.Bytes.
+type CountingReader struct {
+	R io.Reader // Underlying reader
+	N int64     // Count of bytes read
+}
+
+// Read implements io.Reader.
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.R.Read(p)
+	c.N += int64(n)
+	return n, err
+}
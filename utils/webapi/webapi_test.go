@@ -0,0 +1,38 @@
+package webapi_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/woozymasta/steam/utils/webapi"
+)
+
+func TestLogger(t *testing.T) {
+	if webapi.Logger(nil).Enabled(context.Background(), slog.LevelError) {
+		t.Error("nil logger must discard records")
+	}
+
+	l := slog.Default()
+	if webapi.Logger(l) != l {
+		t.Error("logger must be returned as is")
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	cases := map[string]string{
+		"https://api.steampowered.com/I/v1/?key=0123456789ABCDEF&limit=1":  "https://api.steampowered.com/I/v1/?key=****CDEF&limit=1",
+		"https://api.steampowered.com/I/v1/?limit=1&key=0123456789ABCDEF":  "https://api.steampowered.com/I/v1/?limit=1&key=****CDEF",
+		"https://api.steampowered.com/I/v1/?filter=%5Cappid&key=AB%2BCDEF": "https://api.steampowered.com/I/v1/?filter=%5Cappid&key=****CDEF",
+		"https://api.steampowered.com/I/v1/?access_token=secret#top":       "https://api.steampowered.com/I/v1/?access_token=****cret#top",
+		"https://api.steampowered.com/I/v1/?limit=1&monkey=1":              "https://api.steampowered.com/I/v1/?limit=1&monkey=1",
+		"%%bad?key=0123456789ABCDEF&limit=1":                               "%%bad?key=****CDEF&limit=1",
+		"%%bad?key=%zz":                                                    "%%bad?key=****",
+	}
+
+	for in, want := range cases {
+		if got := webapi.RedactURL(in); got != want {
+			t.Errorf("RedactURL(%q) = %q, expected %q", in, got, want)
+		}
+	}
+}